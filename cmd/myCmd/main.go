@@ -21,7 +21,7 @@ func main() {
     }
     defer s.Fini()
 
-    env := tui.NewEnvironment(s, 2, time.Duration(100 * time.Millisecond))
+    env := tui.NewEnvironment(s, tui.UNLIMITED_CAPACITY, time.Duration(100 * time.Millisecond))
 
     pg := "Hello World, wooo, wooo, wooo, wooo, wooo, wooo"
 
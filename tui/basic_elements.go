@@ -8,18 +8,32 @@ import (
 
 // -------------------------------------- Text Element --------------------------------------
 
+// A text element displays styled text laid out according to a text format.
+// (See text.go and markup.go)
 type TextElement struct {
     *DefaultElement
 
+    // Space not covered by text takes this style.
     style tcell.Style
-    text string
+
+    format TextFormat
+    text StyledText
 }
 
 func NewTextElement(s tcell.Style, t string) *TextElement {
+    return NewStyledTextElement(s, NewTextFormat(), PlainText(t, s))
+}
+
+func NewFormattedTextElement(s tcell.Style, tf TextFormat, t string) *TextElement {
+    return NewStyledTextElement(s, tf, PlainText(t, s))
+}
+
+func NewStyledTextElement(s tcell.Style, tf TextFormat, st StyledText) *TextElement {
     return &TextElement{
         DefaultElement: NewDefaultElement(),
         style: s,
-        text: t,
+        format: tf,
+        text: st,
     }
 }
 
@@ -29,41 +43,47 @@ func TextElementF(s tcell.Style, t string) ElementFactory {
     }
 }
 
-func (bt *TextElement) Draw(s tcell.Screen) {
-    if bt.GetRows() == 0 || bt.GetCols() == 0 {
-        return
+func FormattedTextElementF(s tcell.Style, tf TextFormat, t string) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewFormattedTextElement(s, tf, t))
+    }
+}
+
+func StyledTextElementF(s tcell.Style, tf TextFormat, st StyledText) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewStyledTextElement(s, tf, st))
     }
+}
 
-    r := 0
-    c := 0
+// The given markup is parsed on top of the style s. (See ParseMarkup)
+func MarkupTextElementF(s tcell.Style, tf TextFormat, markup string) ElementFactory {
+    return StyledTextElementF(s, tf, ParseMarkup(markup, s))
+}
 
-    for _, ru := range bt.text {
-        s.SetContent(bt.GetC() + c, bt.GetR() + r, 
-            ru, nil, bt.style)     
-    
-        c++
-        if c == bt.GetCols() {
-            c = 0
-            r++
+func (bt *TextElement) GetText() StyledText {
+    return bt.text
+}
 
-            if r == bt.GetRows() {
-                break
-            }
-        }
-    }
+// Changing the text may change the element's size, so a layout is requested.
+func (bt *TextElement) SetText(ectx *ElementContext, st StyledText) {
+    bt.text = st
 
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+}
 
-    for ; r < bt.GetRows(); r++ {
-        for ; c < bt.GetCols(); c++ {
-            s.SetContent(bt.GetC() + c, bt.GetR() + r, 
-                ' ', nil, bt.style)     
-        }
+// A text element would like to display all of its text on as few rows
+// as possible. Its height depends on how its text wraps.
+func (bt *TextElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    hint := bt.DefaultElement.Measure(ectx, cons)
+    hint.PrefRows, hint.PrefCols = measureText(bt.text, cons.MaxCols, bt.format)
 
-        // After completing the last row with text,
-        // We contiue on starting at the beginning of
-        // remaining rows.
-        c = 0
-    }
+    return hint
+}
+
+func (bt *TextElement) Draw(cv *Canvas) {
+    cv.Fill(' ', bt.style)
+    drawText(cv, bt.text, bt.format)
 }
 
 // -------------------------------------- Bordered Element --------------------------------------
@@ -90,29 +110,35 @@ const (
 type BorderedElement struct {
     *DefaultElement
 
-    title string
+    title StyledText
 
-    titleStyle tcell.Style 
     borderStyle tcell.Style
 }
 
 func NewBorderedElement(t string, ts tcell.Style, bs tcell.Style) *BorderedElement {
+    return NewStyledBorderedElement(PlainText(t, ts), bs)
+}
+
+func NewStyledBorderedElement(t StyledText, bs tcell.Style) *BorderedElement {
     return &BorderedElement{
         DefaultElement: NewDefaultElement(),
         title: t,
-        titleStyle: ts,
         borderStyle: bs,
     }
 }
 
 func BorderedElementF(t string, ts tcell.Style, bs tcell.Style, ef ElementFactory) ElementFactory {
+    return StyledBorderedElementF(PlainText(t, ts), bs, ef)
+}
+
+func StyledBorderedElementF(t StyledText, bs tcell.Style, ef ElementFactory) ElementFactory {
     return func (env *Environment) (ElementID, error) {
         cid, err := ef(env) 
         if err != nil {
             return -1, err
         }
 
-        eid, err := env.Register(NewBorderedElement(t, ts, bs))
+        eid, err := env.Register(NewStyledBorderedElement(t, bs))
         if err != nil {
             return -1, err
         }
@@ -134,46 +160,70 @@ func (be *BorderedElement) Resize(ectx *ElementContext, r, c int, rows, cols int
     return err
 }
 
+// A bordered element shrink-wraps its child.
+func (be *BorderedElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    cctx, _ := ectx.Child(0)
+    hint := cctx.Measure(Constraints{
+        MaxRows: max(cons.MaxRows-2, 0), 
+        MaxCols: max(cons.MaxCols-2, 0),
+    })
+
+    hint.PrefRows += 2
+    hint.PrefCols += 2
+    hint.MinRows += 2
+    hint.MinCols += 2
+
+    if hint.MaxRows != UNBOUNDED {
+        hint.MaxRows += 2
+    }
+
+    if hint.MaxCols != UNBOUNDED {
+        hint.MaxCols += 2
+    }
+
+    return hint
+}
+
 func (be *BorderedElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    if IsRoutedEvent(ev) {
+        return nil
+    }
+
     cctx, _ := ectx.Child(0)
     return cctx.ForwardEvent(ev)
 }
 
 
 // This omits left and right endpoints/corners.
-func (be *BorderedElement) drawTitleLine(s tcell.Screen) {
-    if len(be.title) == 0 || be.GetCols() < 7 {
+func (be *BorderedElement) drawTitleLine(cv *Canvas) {
+    if be.title.Len() == 0 || be.GetCols() < 7 {
         for c := 1; c < be.GetCols(); c++ {
-            s.SetContent(be.GetC() + c, be.GetR(), horiz, nil, be.borderStyle)
+            cv.SetContentAt(0, c, horiz, nil, be.borderStyle)
         }
 
         return
     }
 
     // Otherwise we actually draw the title!
-    s.SetContent(be.GetC() + 1, be.GetR(), horiz, nil, be.borderStyle)
-    s.SetContent(be.GetC() + 2, be.GetR(), ' ', nil, be.borderStyle)
-
-    linePos := 3
-    for _, ru := range be.title {
-        s.SetContent(be.GetC() + linePos, be.GetR(), ru, nil, be.titleStyle) 
-        
-        linePos++
-        if linePos == be.GetCols() - 3 {
-            break
-        }
-    }
+    cv.SetContentAt(0, 1, horiz, nil, be.borderStyle)
+    cv.SetContentAt(0, 2, ' ', nil, be.borderStyle)
 
-    s.SetContent(be.GetC() + linePos, be.GetR(), ' ', nil, be.borderStyle)
+    // The title is given cols - 6 columns.
+    // Titles which are too long end in an ellipsis.
+    title := truncateLine(toLine(be.title, NewTextFormat().tabWidth), be.GetCols() - 6, false)
+    drawLine(cv.Sub(0, 3, 1, be.GetCols() - 6), 0, 0, title)
+    linePos := 3 + title.width
+
+    cv.SetContentAt(0, linePos, ' ', nil, be.borderStyle)
     linePos++
 
     for ; linePos < be.GetCols() - 1; linePos++ {
-        s.SetContent(be.GetC() + linePos, be.GetR(), horiz, nil, be.borderStyle)
+        cv.SetContentAt(0, linePos, horiz, nil, be.borderStyle)
     }
 }
 
 // We are just going to redraw the border here...
-func (be *BorderedElement) Draw(s tcell.Screen) {
+func (be *BorderedElement) Draw(cv *Canvas) {
     if be.GetRows() == 0 || be.GetCols() == 0 {
         return
     }
@@ -193,22 +243,22 @@ func (be *BorderedElement) Draw(s tcell.Screen) {
 
     // Single column. rows >= 2.
     if be.GetCols() == 1 {
-        s.SetContent(be.GetC(), be.GetR(), topend, nil, be.borderStyle)
+        cv.SetContentAt(0, 0, topend, nil, be.borderStyle)
 
         for r := 1; r < be.GetRows() - 1; r++ {
-            s.SetContent(be.GetC(), be.GetR() + r, vert, nil, be.borderStyle)
+            cv.SetContentAt(r, 0, vert, nil, be.borderStyle)
         }
 
-        s.SetContent(be.GetC(), be.GetR() + be.GetRows() - 1, bottomend, nil, be.borderStyle)
+        cv.SetContentAt(be.GetRows() - 1, 0, bottomend, nil, be.borderStyle)
 
         return
     }
 
     // Single row. cols >= 2
     if be.GetRows() == 1 {
-        s.SetContent(be.GetC(), be.GetR(), leftend, nil, be.borderStyle)
-        be.drawTitleLine(s)
-        s.SetContent(be.GetC() + be.GetCols() - 1, be.GetR(), rightend, nil, be.borderStyle)
+        cv.SetContentAt(0, 0, leftend, nil, be.borderStyle)
+        be.drawTitleLine(cv)
+        cv.SetContentAt(0, be.GetCols() - 1, rightend, nil, be.borderStyle)
 
         return
     }
@@ -216,179 +266,173 @@ func (be *BorderedElement) Draw(s tcell.Screen) {
     // be.GetRows() >= 2 && be.GetCols() >= 2
 
     // Title Line
-    be.drawTitleLine(s)
+    be.drawTitleLine(cv)
 
     // Bottom Horizontal borders.
     for c := 1; c < be.GetCols() - 1; c++ {
-        s.SetContent(be.GetC() + c, be.GetR() + be.GetRows() - 1, horiz, nil, be.borderStyle)
+        cv.SetContentAt(be.GetRows() - 1, c, horiz, nil, be.borderStyle)
     }
 
     // Vertical borders.
     for r := 1; r < be.GetRows() - 1; r++ {
-        s.SetContent(be.GetC(), be.GetR() + r, vert, nil, be.borderStyle)
-        s.SetContent(be.GetC() + be.GetCols() - 1, be.GetR() + r, vert, nil, be.borderStyle)
+        cv.SetContentAt(r, 0, vert, nil, be.borderStyle)
+        cv.SetContentAt(r, be.GetCols() - 1, vert, nil, be.borderStyle)
     }
 
     // Corners.
-    s.SetContent(be.GetC(), be.GetR(), topleft, nil, be.borderStyle)
-    s.SetContent(be.GetC() + be.GetCols() - 1, be.GetR(), topright, nil, be.borderStyle)
-    s.SetContent(be.GetC() + be.GetCols() - 1, be.GetR() + be.GetRows() - 1, bottomright, nil, be.borderStyle)
-    s.SetContent(be.GetC(), be.GetR() + be.GetRows() - 1, bottomleft, nil, be.borderStyle)
+    cv.SetContentAt(0, 0, topleft, nil, be.borderStyle)
+    cv.SetContentAt(0, be.GetCols() - 1, topright, nil, be.borderStyle)
+    cv.SetContentAt(be.GetRows() - 1, be.GetCols() - 1, bottomright, nil, be.borderStyle)
+    cv.SetContentAt(be.GetRows() - 1, 0, bottomleft, nil, be.borderStyle)
 }
 
 // -------------------------------------- Divided Element --------------------------------------
 
-type DivisionSpec interface {
-    IsFixed() bool
-    FixedSize() int
-
-    IsFlexible() bool
-    FlexFactor() int
-}
+// A divided element holds a variable number of child elements. 
+// A divided element CAN hold zero elements.
+// A divided element can hold column divisions or row division, but not both.
+//
+// A division's size can be variable (with a flex coeficient),
+// fixed (with an exact row or column amount), a percentage of the divided 
+// element, or sized to fit its element. (See division.go)
 
-type FixedSpec struct {
-    fixedSize int
-}
+type DividedElement struct {
+    *DefaultElement
+    
+    // true = column divisions.
+    // false = row divisions.
+    columnDivisions bool   
 
-func (fs FixedSpec) IsFixed() bool {
-    return true
-}
+    // If true, there were will line dividers between each division.
+    dividers bool
 
-func (fs FixedSpec) FixedSize() int {
-    return fs.fixedSize
-}
+    // NOTE: Since a divided element can hold no elements and/or
+    // not be entirely filled by its children, extra space (and dividers) will
+    // take this style.
+    style tcell.Style
 
-func (fs FixedSpec) IsFlexible() bool {
-    return false
+    // Offsets (relative to this element) of each divider line.
+    // These are calculated during resize.
+    dividerPositions []int
 }
 
-func (fs FixedSpec) FlexFactor() int {
-    return 0
-}
+// The child attribute key which all children of a divided element
+// must have.
+const DIV_SPEC_ATTR = "div-spec"
 
-type FlexSpec struct {
-    flexFactor int
+func NewDividedElement(cd bool, d bool, s tcell.Style) *DividedElement {
+    return &DividedElement{
+        DefaultElement: NewDefaultElement(),
+        columnDivisions: cd,
+        dividers: d,
+        style: s,
+        dividerPositions: make([]int, 0),
+    }
 }
 
-func (fs FlexSpec) IsFixed() bool {
-    return false
+// A division pairs an element factory with the spec its
+// element should be given inside a divided element.
+type Division struct {
+    spec DivisionSpec
+    ef ElementFactory
 }
 
-func (fs FlexSpec) FixedSize() int {
-    return 0
+func FixedDivision(size int, ef ElementFactory) Division {
+    return Division{
+        spec: NewFixedSpec(size),
+        ef: ef,
+    }
 }
 
-func (fs FlexSpec) IsFlexible() bool {
-    return true
+func FlexDivision(factor int, ef ElementFactory) Division {
+    return Division{
+        spec: NewFlexSpec(factor),
+        ef: ef,
+    }
 }
 
-func (fs FlexSpec) FlexFactor() int {
-    return fs.flexFactor
+func BoundedFlexDivision(factor int, min int, max int, ef ElementFactory) Division {
+    return Division{
+        spec: NewBoundedFlexSpec(factor, min, max),
+        ef: ef,
+    }
 }
 
-// Helper function for calculating dimmensions of child elements.
-func mapToDims(totalDim int, specs []DivisionSpec) ([]int, error) { 
-    totalFixedDim := 0
-    totalFlexFactor := 0
-    for i, spec := range specs {
-        if spec.IsFixed() {
-            if spec.FixedSize() < 0 {
-                return nil, fmt.Errorf("mapToDims: Negative fixed dim: %d", i)
-            }
-            
-            totalFixedDim += spec.FixedSize()
-        } else {
-            if spec.FlexFactor() < 0 {
-                return nil, fmt.Errorf("mapToDims: Negative flex factor: %d", i)
-            }
-
-            totalFlexFactor += spec.FlexFactor()
-        }
+func PercentDivision(p int, ef ElementFactory) Division {
+    return Division{
+        spec: NewPercentSpec(p),
+        ef: ef,
     }
+}
 
-    flexUnit := 0
-    if totalFlexFactor > 0 && totalFixedDim < totalDim {
-        flexUnit := (totalDim - totalFixedDim) / totalFlexFactor
+func AutoDivision(min int, max int, ef ElementFactory) Division {
+    return Division{
+        spec: NewAutoSpec(min, max),
+        ef: ef,
     }
+}
 
-    dims := make([]int, len(specs)) 
-
-    pos := 0
-    for i, spec := range specs {
-        spaceLeft := totalDim - pos 
-
-        dims[i] = 0
-
-        // NOTE: if we reach a fixed size division which cannot be drawn,
-        // Everything is given size 0! There is simply not enough room!
-        if spec.IsFixed() {
-            if spec.FixedSize() > spaceLeft {
-                return make([]int, len(specs)), nil
-            }
-            
-            dims[i] = spec.FixedSize()
-        } else {
-            dims[i] = spec.FlexFactor() * flexUnit
+func DividedElementF(cd bool, d bool, s tcell.Style, divs ...Division) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        eid, err := env.Register(NewDividedElement(cd, d, s))
+        if err != nil {
+            return -1, err
         }
 
-        // Advance our position.
-        pos += dims[i]
-    }
-
-    // NOTE: If there is space left after calculating the lengths
-    // Just add it to the first flex division found.
-    // If there are no flex divisions, this area will be left blank.
-    // Fixed divisions NEVER resize.
-    if pos < totalDim {
-        spaceLeft := totalDim - pos 
-        for i, spec := range specs {
-            if spec.IsFlexible() {
-                dims[i] += spaceLeft
+        for _, div := range divs {
+            cid, err := div.ef(env)
+            if err != nil {
+                // Earlier children are deregistered along with the
+                // parent. This will never error.
+                env.Deregister(eid)
+                return -1, err
             }
+
+            // These will never error.
+            index, _ := env.Attach(eid, cid)
+            env.SetChildAttr(eid, index, DIV_SPEC_ATTR, div.spec)
         }
+
+        return eid, nil
     }
 }
 
-// A divided element holds a variable number of child elements. 
-// A divided element CAN hold zero elements.
-// A divided element can hold column divisions or row division, but not both.
+// Creates, registers and attaches a new division at the end of the given 
+// divided element's context.
 //
-// A division's size can be variable (with a flex coeficient)
-// Or a division's size can be fixed (with an exact row or column amount)
-
-type DividedElement struct {
-    *DefaultElement
-    
-    // true = column divisions.
-    // false = row divisions.
-    columnDivisions bool   
+// NOTE: The divided element will need to be resized for the new division to be
+// given space.
+func AttachDivision(ectx *ElementContext, div Division) (int, error) {
+    index, err := ectx.CreateRegisterAndAttach(div.ef)
+    if err != nil {
+        return -1, fmt.Errorf("AttachDivision: %w", err)
+    }
 
-    // If true, there were will line dividers between each division.
-    dividers bool
+    // This will never error.
+    ectx.SetChildAttr(index, DIV_SPEC_ATTR, div.spec)
 
-    // NOTE: Since a divided element can hold no elements and/or
-    // not be entirely filled by its children, extra space (and dividers) will
-    // take this style.
-    style tcell.Style
+    return index, nil
 }
 
 // NOTE:
 // All children need a "div-spec" attribute which maps to a DivisionSpec.
 // This attribute determines how the child will be resized.
 //
-// A fixed division is either displayed at its specified size, or not displayed at all.
-// The moment a fixed division cannot be displayed, drawing stops, no subsequent flex divisions
-// will be displayed. flex divisions are only displayed if there is enough room including all fixed 
-// divisions.
+// When there is not enough room for every division, divisions shrink gracefully
+// towards their minimums. If even the minimums do not fit, the last divisions
+// are cut off.
+//
+// If dividers are enabled, one row or column is reserved between each pair of
+// adjacent divisions.
 
 func (de *DividedElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
-    de.DefaultElement.Resize(ectx, r, c, rows, cols)
+    err := de.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
 
     numChildren := ectx.NumChildren()
 
-    totalFixed := 0
-    totalFlex := 0
-    
     var totalDim int
     if de.columnDivisions {
         totalDim = cols
@@ -396,69 +440,169 @@ func (de *DividedElement) Resize(ectx *ElementContext, r, c int, rows, cols int)
         totalDim = rows
     }
 
-    // First, let's extract the division specs.
+    // First, let's extract the division specs and preferred sizes.
     specs := make([]DivisionSpec, numChildren)   
+    prefs := make([]int, numChildren)
     for i := 0; i < numChildren; i++ {
-        val, err := ectx.GetChildAttr(i, "div-spec")
+        val, err := ectx.GetChildAttr(i, DIV_SPEC_ATTR)
         if err != nil {
-            return err
+            return fmt.Errorf("Resize: %w", err)
         }
 
         ds, ok := val.(DivisionSpec)
         if !ok {
-            return fmt.Errorf("Resize: div-spec has incorred type: %d", i)
+            return fmt.Errorf("Resize: div-spec has incorrect type: %d", i)
         }
 
         specs[i] = ds
 
-        if ds.IsFixed() {
-            totalFixed += ds.FixedSize()
+        cctx, _ := ectx.Child(i)
+        hint := cctx.Measure(Constraints{MaxRows: rows, MaxCols: cols})
+
+        if de.columnDivisions {
+            prefs[i] = hint.PrefCols
         } else {
-            totalFlex += ds.FlexFactor()
+            prefs[i] = hint.PrefRows
         }
     }
 
-    // flexUnit = (rows or columns) per unit of flex.
-    flexUnit := 0
-    if totalFixed < totalDim && totalFlex > 0 {
-        flexUnit = (totalDim - totalFixed) / totalFlex
+    numDividers := 0
+    if de.dividers && numChildren > 1 {
+        numDividers = numChildren - 1
     }
 
+    var dims []int
+    if numDividers > totalDim {
+        // Not even the dividers fit, nothing is displayed.
+        dims = make([]int, numChildren)
+        numDividers = 0
+    } else {
+        dims, err = mapToDims(totalDim - numDividers, specs, prefs)
+        if err != nil {
+            return fmt.Errorf("Resize: %w", err)
+        }
+    }
+
+    de.dividerPositions = de.dividerPositions[:0]
+
     pos := 0
-    index := 0
-    for ; index < numChildren; index++ {
-        spaceLeft := totalDim - pos
+    for i := 0; i < numChildren; i++ {
+        cctx, _ := ectx.Child(i)
 
-        var dim int   
-        if spec.IsFixed() {
-            dim = spec.FixedSize()
+        if de.columnDivisions {
+            err = cctx.ForwardResize(r, c + pos, rows, dims[i])
         } else {
-            dim = spec.FlexFactor() * flexUnit
+            err = cctx.ForwardResize(r + pos, c, dims[i], cols)
+        }
+
+        if err != nil {
+            return err
         }
 
-        if dim > spaceLeft {
-            dim = 0
+        pos += dims[i]
+
+        if i < numDividers {
+            de.dividerPositions = append(de.dividerPositions, pos)
+            pos++
         }
+    }
+
+    return nil
+}
+
+// Along its divisions, a divided element would like enough room for every
+// division's basis. Across its divisions, it would like enough room for 
+// its largest division.
+func (de *DividedElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    hint := de.DefaultElement.Measure(ectx, cons)
 
-        
+    numChildren := ectx.NumChildren()
+
+    var totalDim int
+    if de.columnDivisions {
+        totalDim = cons.MaxCols
+    } else {
+        totalDim = cons.MaxRows
     }
 
-    for i, spec := range specs {
-        spaceLeft := totalDim - pos
+    along := 0
+    across := 0
 
-        var dim int   
-        if spec.IsFixed() {
-            dim = spec.FixedSize()
-        } else {
-            dim = spec.FlexFactor() * flexUnit
+    for i := 0; i < numChildren; i++ {
+        cctx, _ := ectx.Child(i)
+        chint := cctx.Measure(cons)
+
+        pref := chint.PrefRows
+        crossPref := chint.PrefCols
+        if de.columnDivisions {
+            pref = chint.PrefCols
+            crossPref = chint.PrefRows
+        }
+
+        across = max(across, crossPref)
+
+        // Children without a valid spec are ignored here, Resize will
+        // report the error.
+        val, err := ectx.GetChildAttr(i, DIV_SPEC_ATTR)
+        if err != nil {
+            continue
         }
 
-        if dim == 0 || {
+        ds, ok := val.(DivisionSpec)
+        if !ok {
+            continue
+        }
 
+        along += ds.Resolve(totalDim, pref).basis
     }
 
-    return nil
+    if de.dividers && numChildren > 1 {
+        along += numChildren - 1
+    }
+
+    if de.columnDivisions {
+        hint.PrefCols = along
+        hint.PrefRows = across
+    } else {
+        hint.PrefRows = along
+        hint.PrefCols = across
+    }
+
+    return hint
 }
 
+// Events are forwarded to every division.
+func (de *DividedElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    if IsRoutedEvent(ev) {
+        return nil
+    }
 
+    for i := 0; i < ectx.NumChildren(); i++ {
+        cctx, _ := ectx.Child(i)
+
+        err := cctx.ForwardEvent(ev)
+        if err != nil {
+            return err
+        }
+    }
 
+    return nil
+}
+
+// This clears the divided element's area and draws the dividers.
+// Divisions draw themselves afterwards.
+func (de *DividedElement) Draw(cv *Canvas) {
+    cv.Fill(' ', de.style)
+
+    for _, pos := range de.dividerPositions {
+        if de.columnDivisions {
+            for i := 0; i < de.GetRows(); i++ {
+                cv.SetContentAt(i, pos, vert, nil, de.style)
+            }
+        } else {
+            for j := 0; j < de.GetCols(); j++ {
+                cv.SetContentAt(pos, j, horiz, nil, de.style)
+            }
+        }
+    }
+}
@@ -0,0 +1,140 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+var (
+    plain = tcell.StyleDefault
+    blue = tcell.StyleDefault.Foreground(tcell.ColorBlue)
+    red = tcell.StyleDefault.Foreground(tcell.ColorRed)
+)
+
+func TestTextElement(t *testing.T) {
+    cases := []struct {
+        name string
+        rows, cols int
+        ef tui.ElementFactory
+    }{
+        {"text_plain", 3, 12, tui.TextElementF(blue, "Hello\nWorld")},
+        {"text_word_wrap", 4, 10, tui.FormattedTextElementF(plain,
+            tui.NewTextFormat().WithWrap(tui.WRAP_WORD),
+            "the quick brown fox jumps")},
+        {"text_centered", 3, 11, tui.FormattedTextElementF(plain,
+            tui.NewTextFormat().WithAlign(tui.ALIGN_CENTER, tui.ALIGN_MIDDLE),
+            "mid")},
+        {"text_ellipsis", 1, 8, tui.FormattedTextElementF(plain,
+            tui.NewTextFormat().WithEllipsis(),
+            "far too long to fit")},
+        {"text_markup", 1, 16, tui.MarkupTextElementF(plain, tui.NewTextFormat(),
+            "[::b]bold[-] [red]red[-]")},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            h := tuitest.New(t, tc.rows, tc.cols, tc.ef)
+            h.AssertGolden(tc.name)
+        })
+    }
+}
+
+func TestTextElementSetText(t *testing.T) {
+    te := tui.NewTextElement(plain, "before")
+    var eid tui.ElementID
+
+    h := tuitest.New(t, 1, 10, func (env *tui.Environment) (tui.ElementID, error) {
+        var err error
+        eid, err = env.Register(te)
+        return eid, err
+    })
+
+    h.Env().Post(func (env *tui.Environment) {
+        ectx, _ := env.GetElementContext(eid)
+        te.SetText(ectx, tui.PlainText("after", red))
+    })
+
+    h.Flush()
+    h.AssertGolden("text_set_text")
+}
+
+func TestBorderedElement(t *testing.T) {
+    cases := []struct {
+        name string
+        rows, cols int
+        ef tui.ElementFactory
+    }{
+        {"bordered_no_title", 4, 8, tui.BorderedElementF("", plain, blue,
+            tui.TextElementF(plain, "hi"))},
+        {"bordered_title", 4, 14, tui.BorderedElementF("Title", red, blue,
+            tui.TextElementF(plain, "body"))},
+        {"bordered_long_title", 3, 8, tui.BorderedElementF("Much too long", red, blue,
+            tui.TextElementF(plain, ""))},
+        {"bordered_tiny", 2, 2, tui.BorderedElementF("T", red, blue,
+            tui.TextElementF(plain, "x"))},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            h := tuitest.New(t, tc.rows, tc.cols, tc.ef)
+            h.AssertGolden(tc.name)
+        })
+    }
+}
+
+func TestBorderedElementResize(t *testing.T) {
+    h := tuitest.New(t, 3, 10, tui.BorderedElementF("Box", red, blue,
+        tui.TextElementF(plain, "abc")))
+    h.AssertGolden("bordered_before_resize")
+
+    h.Resize(5, 14)
+    h.AssertGolden("bordered_after_resize")
+}
+
+func TestDividedElementLayout(t *testing.T) {
+    cases := []struct {
+        name string
+        rows, cols int
+        ef tui.ElementFactory
+    }{
+        {"divided_flex", 3, 20, tui.DividedElementF(true, true, plain,
+            tui.FixedDivision(4, tui.TextElementF(red, "fixd")),
+            tui.FlexDivision(1, tui.TextElementF(blue, "one")),
+            tui.FlexDivision(2, tui.TextElementF(plain, "two")),
+        )},
+        {"divided_percent_auto", 6, 10, tui.DividedElementF(false, false, plain,
+            tui.PercentDivision(50, tui.TextElementF(red, "half")),
+            tui.AutoDivision(0, tui.UNBOUNDED, tui.TextElementF(blue, "a\nb")),
+        )},
+        {"divided_shrink", 2, 10, tui.DividedElementF(true, false, plain,
+            tui.FixedDivision(8, tui.TextElementF(red, "eeeeeeee")),
+            tui.FixedDivision(4, tui.TextElementF(blue, "ffff")),
+        )},
+        {"divided_bounded_flex", 1, 20, tui.DividedElementF(true, false, plain,
+            tui.BoundedFlexDivision(1, 0, 3, tui.TextElementF(red, "capped")),
+            tui.FlexDivision(1, tui.TextElementF(blue, "rest")),
+        )},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            h := tuitest.New(t, tc.rows, tc.cols, tc.ef)
+            h.AssertGolden(tc.name)
+        })
+    }
+}
+
+func TestGridElementLayout(t *testing.T) {
+    specs := []tui.DivisionSpec{tui.NewFixedSpec(3), tui.NewFlexSpec(1)}
+
+    h := tuitest.New(t, 4, 10, tui.GridElementF(specs, specs, plain,
+        tui.GridItemAt(0, 0, tui.TextElementF(red, "a")),
+        tui.GridItemAt(0, 1, tui.TextElementF(blue, "b")),
+        tui.GridSpanAt(1, 0, 1, 2, tui.TextElementF(plain, "spans both")),
+    ))
+
+    h.AssertGolden("grid_span")
+}
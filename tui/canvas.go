@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Canvas --------------------------------------
+
+// A canvas is what an element draws to. (See Element.Draw)
+//
+// A canvas uses local coordinates, (0, 0) is the top left cell of the
+// canvas. Nothing can be drawn outside of the canvas, drawing out of bounds
+// is silently ignored.
+type Canvas struct {
+    s tcell.Screen
+
+    // Where the canvas's (0, 0) lies on s.
+    r, c int
+
+    rows, cols int
+
+    // The cells of s which can be drawn to.
+    // This can be smaller than the canvas itself. (See Sub)
+    clip Rect
+}
+
+// Returns a canvas covering the given rectangle of s.
+func NewCanvas(s tcell.Screen, r, c int, rows, cols int) *Canvas {
+    rows = max(rows, 0)
+    cols = max(cols, 0)
+
+    return &Canvas{
+        s: s,
+        r: r,
+        c: c,
+        rows: rows,
+        cols: cols,
+        clip: NewRect(r, c, rows, cols),
+    }
+}
+
+func (cv *Canvas) Rows() int {
+    return cv.rows
+}
+
+func (cv *Canvas) Cols() int {
+    return cv.cols
+}
+
+// Returns the canvas covering the given rectangle of this canvas.
+// The new canvas is still clipped to this canvas.
+func (cv *Canvas) Sub(r, c int, rows, cols int) *Canvas {
+    rows = max(rows, 0)
+    cols = max(cols, 0)
+
+    return &Canvas{
+        s: cv.s,
+        r: cv.r + r,
+        c: cv.c + c,
+        rows: rows,
+        cols: cols,
+        clip: cv.clip.Intersect(NewRect(cv.r + r, cv.c + c, rows, cols)),
+    }
+}
+
+func (cv *Canvas) inBounds(r, c int) bool {
+    return cv.clip.ContainsPoint(cv.r + r, cv.c + c)
+}
+
+// NOTE: Unlike tcell.Screen, canvas methods always take the row first.
+func (cv *Canvas) SetContentAt(r, c int, primary rune, combining []rune, style tcell.Style) {
+    if cv.inBounds(r, c) {
+        cv.s.SetContent(cv.c + c, cv.r + r, primary, combining, style)
+    }
+}
+
+func (cv *Canvas) SetCellAt(r, c int, ru rune, style tcell.Style) {
+    cv.SetContentAt(r, c, ru, nil, style)
+}
+
+// Sets every cell in the given rectangle.
+func (cv *Canvas) FillRect(r, c int, rows, cols int, ru rune, style tcell.Style) {
+    area := NewRect(0, 0, cv.rows, cv.cols).Intersect(NewRect(r, c, rows, cols))
+
+    for i := area.R; i < area.R + area.Rows; i++ {
+        for j := area.C; j < area.C + area.Cols; j++ {
+            cv.SetContentAt(i, j, ru, nil, style)
+        }
+    }
+}
+
+// Sets every cell of the canvas.
+func (cv *Canvas) Fill(ru rune, style tcell.Style) {
+    cv.FillRect(0, 0, cv.rows, cv.cols, ru, style)
+}
+
+// Prints the given text on row r starting at column c.
+// Text does not wrap, whatever does not fit is cut off. (See text.go)
+//
+// Returns the column after the last printed glyph.
+func (cv *Canvas) PrintStyled(r, c int, text string, style tcell.Style) int {
+    return cv.PrintText(r, c, PlainText(text, style))
+}
+
+// PrintStyled for styled text. (See markup.go)
+func (cv *Canvas) PrintText(r, c int, st StyledText) int {
+    line := toLine(st, NewTextFormat().tabWidth)
+    drawLine(cv, r, c, line)
+
+    return min(c + line.width, cv.cols)
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"fmt"
+)
+
+// -------------------------------------- Cursor --------------------------------------
+
+// The terminal has a single cursor. An element asks for the cursor to be
+// shown at one of its cells through ShowCursor. The environment places the
+// cursor after each draw.
+//
+// The cursor is only shown while the element which asked for it has focus
+// and the requested cell is visible on screen.
+
+type cursorState struct {
+    // NULL_EID if no element wants the cursor shown.
+    eid ElementID
+
+    // In the coordinate space of eid. (Relative to its origin)
+    r, c int
+}
+
+// Returns the frame which the given element is drawn through.
+// (See damage.go)
+func (env *Environment) screenFrame(eid ElementID) frame {
+    path := make([]ElementID, 0)
+    for ; eid != NULL_EID; eid = env.entry(eid).ectx.parentID {
+        path = append(path, eid)
+    }
+
+    cols, rows := env.screen.Size()
+    f := frame{clip: NewRect(0, 0, rows, cols)}
+
+    // Apply viewports from the top of the tree down.
+    for i := len(path) - 1; i >= 0; i-- {
+        if vp := env.entry(path[i]).ectx.viewport; vp != nil {
+            f = f.enter(*vp)
+        }
+    }
+
+    return f
+}
+
+// Asks for the cursor to be shown at cell (r, c) of the given element.
+func (env *Environment) ShowCursor(eid ElementID, r, c int) error {
+    _, err := env.getEnvEntry(eid)
+    if err != nil {
+        return fmt.Errorf("ShowCursor: %w", err)
+    }
+
+    env.cursor = cursorState{eid: eid, r: r, c: c}
+    return nil
+}
+
+// Hides the cursor if the given element asked for it to be shown.
+func (env *Environment) HideCursor(eid ElementID) {
+    if env.cursor.eid == eid {
+        env.cursor = cursorState{eid: NULL_EID}
+    }
+}
+
+// Returns where the cursor should be on screen.
+// ok is false if the cursor should be hidden.
+func (env *Environment) cursorPosition() (int, int, bool) {
+    eid := env.cursor.eid
+    if eid == NULL_EID || eid != env.focusID {
+        return 0, 0, false
+    }
+
+    if _, err := env.getEnvEntry(eid); err != nil {
+        return 0, 0, false
+    }
+
+    ectx := env.entry(eid).ectx
+    f := env.screenFrame(eid)
+
+    r := f.dr + ectx.r + env.cursor.r
+    c := f.dc + ectx.c + env.cursor.c
+
+    if !ectx.drawnRect.ContainsPoint(r, c) || !f.clip.ContainsPoint(r, c) {
+        return 0, 0, false
+    }
+
+    return r, c, true
+}
+
+// Moves the terminal cursor to where it should be.
+// Returns true if the cursor changed.
+func (env *Environment) placeCursor() bool {
+    r, c, ok := env.cursorPosition()
+
+    placed := cursorState{eid: NULL_EID}
+    if ok {
+        placed = cursorState{eid: env.cursor.eid, r: r, c: c}
+    }
+
+    if placed == env.placedCursor {
+        return false
+    }
+
+    env.placedCursor = placed
+
+    if ok {
+        env.screen.ShowCursor(c, r)
+    } else {
+        env.screen.HideCursor()
+    }
+
+    return true
+}
@@ -0,0 +1,239 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Damage Tracking --------------------------------------
+
+// Drawing happens in two passes. (See Environment.Draw)
+//
+// 1) Every element whose draw flag is set (or whose place on screen changed)
+//    marks its on screen rectangle as damaged.
+//
+// 2) Every element which overlaps a damaged region is drawn, in tree order.
+//    All drawing is clipped to the damaged regions.
+//
+// This way, when a parent draws over its area, the descendants (and later
+// siblings) it covered are always drawn again on top of it. Since drawing is
+// clipped, nothing outside of the damaged regions ever changes.
+
+// A rectangle of cells.
+type Rect struct {
+    R, C int
+    Rows, Cols int
+}
+
+func NewRect(r, c int, rows, cols int) Rect {
+    return Rect{
+        R: r,
+        C: c,
+        Rows: rows,
+        Cols: cols,
+    }
+}
+
+func (rect Rect) Empty() bool {
+    return rect.Rows <= 0 || rect.Cols <= 0
+}
+
+func (rect Rect) Contains(other Rect) bool {
+    return other.Empty() || (!rect.Empty() &&
+        rect.R <= other.R && other.R + other.Rows <= rect.R + rect.Rows &&
+        rect.C <= other.C && other.C + other.Cols <= rect.C + rect.Cols)
+}
+
+func (rect Rect) ContainsPoint(r, c int) bool {
+    return rect.R <= r && r < rect.R + rect.Rows &&
+        rect.C <= c && c < rect.C + rect.Cols
+}
+
+// Returns the overlap of two rectangles, which may be empty.
+func (rect Rect) Intersect(other Rect) Rect {
+    r := max(rect.R, other.R)
+    c := max(rect.C, other.C)
+
+    rows := min(rect.R + rect.Rows, other.R + other.Rows) - r
+    cols := min(rect.C + rect.Cols, other.C + other.Cols) - c
+
+    if rows <= 0 || cols <= 0 {
+        return Rect{}
+    }
+
+    return NewRect(r, c, rows, cols)
+}
+
+func (rect Rect) Overlaps(other Rect) bool {
+    return !rect.Intersect(other).Empty()
+}
+
+// Returns the smallest rectangle containing both rectangles.
+func (rect Rect) Union(other Rect) Rect {
+    if rect.Empty() {
+        return other
+    }
+
+    if other.Empty() {
+        return rect
+    }
+
+    r := min(rect.R, other.R)
+    c := min(rect.C, other.C)
+
+    return NewRect(r, c,
+        max(rect.R + rect.Rows, other.R + other.Rows) - r,
+        max(rect.C + rect.Cols, other.C + other.Cols) - c)
+}
+
+// When there are more damaged regions than this, they are all merged
+// into one. This keeps overlap checks cheap.
+const maxDamageRects = 16
+
+// Marks the given screen region as needing to be redrawn.
+func (env *Environment) addDamage(rect Rect) {
+    if rect.Empty() {
+        return
+    }
+
+    kept := env.damage[:0]
+    for _, d := range env.damage {
+        if d.Contains(rect) {
+            return
+        }
+
+        if !rect.Contains(d) {
+            kept = append(kept, d)
+        }
+    }
+
+    env.damage = append(kept, rect)
+
+    if len(env.damage) > maxDamageRects {
+        merged := Rect{}
+        for _, d := range env.damage {
+            merged = merged.Union(d)
+        }
+
+        env.damage = append(env.damage[:0], merged)
+    }
+}
+
+func (env *Environment) damaged(rect Rect) bool {
+    for _, d := range env.damage {
+        if d.Overlaps(rect) {
+            return true
+        }
+    }
+
+    return false
+}
+
+// The entire screen will be redrawn during the next draw.
+// Use this when the screen was changed from outside of the environment.
+// (For example, after it is cleared)
+func (env *Environment) Invalidate() {
+    cols, rows := env.screen.Size()
+
+    env.damage = env.damage[:0]
+    env.addDamage(NewRect(0, 0, rows, cols))
+}
+
+// Marks the regions the given element and its descendants were last drawn
+// to as damaged. Used when elements leave the screen.
+func (env *Environment) damageTree(eid ElementID) {
+    ectx := env.entry(eid).ectx
+
+    env.addDamage(ectx.drawnRect)
+    ectx.drawnRect = Rect{}
+
+    for _, cctx := range ectx.children {
+        env.damageTree(cctx.id)
+    }
+}
+
+// A frame describes how the coordinates of an element map to the screen.
+// Local coordinates are translated by (dr, dc) then clipped to clip.
+// (See Viewport)
+type frame struct {
+    dr, dc int
+    clip Rect
+}
+
+// Returns the frame of the children of an element in frame f with the
+// given viewport.
+func (f frame) enter(vp Viewport) frame {
+    window := NewRect(vp.y + f.dr, vp.x + f.dc, vp.height, vp.width)
+
+    return frame{
+        dr: f.dr + vp.y - vp.yOff,
+        dc: f.dc + vp.x - vp.xOff,
+        clip: f.clip.Intersect(window),
+    }
+}
+
+// Where the given local rectangle appears on screen.
+func (f frame) screenRect(r, c int, rows, cols int) Rect {
+    return f.clip.Intersect(NewRect(r + f.dr, c + f.dc, rows, cols))
+}
+
+// Damage pass helper. (See above)
+func (env *Environment) collectDamage(eid ElementID, f frame) {
+    ee := env.entry(eid)
+    ectx := ee.ectx
+
+    // Hidden elements were damaged when they were hidden.
+    if ectx.hidden {
+        return
+    }
+
+    if ectx.viewport != nil {
+        f = f.enter(*(ectx.viewport))
+    }
+
+    rect := f.screenRect(ectx.r, ectx.c, ectx.rows, ectx.cols)
+
+    if rect != ectx.drawnRect {
+        // The element moved, whatever was under its old position
+        // must be drawn again.
+        env.addDamage(ectx.drawnRect)
+        env.addDamage(rect)
+        ectx.drawnRect = rect
+    } else if ee.e.GetDrawFlag() {
+        env.addDamage(rect)
+    }
+
+    for _, cctx := range ectx.children {
+        env.collectDamage(cctx.id, f)
+    }
+}
+
+// Returns where the given element appears on screen.
+// (Clipped by the viewports of the element and its ancestors)
+func (env *Environment) screenRect(eid ElementID) Rect {
+    ectx := env.entry(eid).ectx
+    return env.screenFrame(eid).screenRect(ectx.r, ectx.c, ectx.rows, ectx.cols)
+}
+
+// A damage screen drops all drawing outside of the damaged regions.
+type damageScreen struct {
+    tcell.Screen
+
+    env *Environment
+}
+
+func (ds *damageScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+    for _, d := range ds.env.damage {
+        if d.ContainsPoint(y, x) {
+            ds.Screen.SetContent(x, y, primary, combining, style)
+            return
+        }
+    }
+}
+
+func (ds *damageScreen) SetCell(x, y int, style tcell.Style, ch ...rune) {
+    if len(ch) > 0 {
+        ds.SetContent(x, y, ch[0], ch[1:], style)
+    } else {
+        ds.SetContent(x, y, ' ', nil, style)
+    }
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRect(t *testing.T) {
+    a := NewRect(0, 0, 4, 4)
+    b := NewRect(2, 2, 4, 4)
+    c := NewRect(10, 10, 1, 1)
+
+    cases := []struct {
+        name string
+        actual Rect
+        expected Rect
+    }{
+        {"intersect", a.Intersect(b), NewRect(2, 2, 2, 2)},
+        {"intersect disjoint", a.Intersect(c), Rect{}},
+        {"intersect touching", a.Intersect(NewRect(0, 4, 4, 4)), Rect{}},
+        {"union", a.Union(b), NewRect(0, 0, 6, 6)},
+        {"union empty", a.Union(Rect{}), a},
+        {"union into empty", Rect{}.Union(c), c},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            if tc.actual != tc.expected {
+                t.Errorf("expected %v, got %v", tc.expected, tc.actual)
+            }
+        })
+    }
+
+    if !a.Contains(NewRect(1, 1, 2, 2)) || a.Contains(b) || !a.Contains(Rect{}) {
+        t.Error("Contains is wrong")
+    }
+
+    if !a.Overlaps(b) || a.Overlaps(c) {
+        t.Error("Overlaps is wrong")
+    }
+}
+
+func TestAddDamage(t *testing.T) {
+    cases := []struct {
+        name string
+        added []Rect
+        expected []Rect
+    }{
+        {"empty ignored", []Rect{{}, NewRect(0, 0, 0, 5)}, []Rect{}},
+        {"disjoint kept", []Rect{NewRect(0, 0, 1, 1), NewRect(5, 5, 1, 1)},
+            []Rect{NewRect(0, 0, 1, 1), NewRect(5, 5, 1, 1)}},
+        {"contained dropped", []Rect{NewRect(0, 0, 4, 4), NewRect(1, 1, 2, 2)},
+            []Rect{NewRect(0, 0, 4, 4)}},
+        {"containing replaces", []Rect{NewRect(1, 1, 1, 1), NewRect(3, 3, 1, 1), NewRect(0, 0, 5, 5)},
+            []Rect{NewRect(0, 0, 5, 5)}},
+        {"overlapping kept", []Rect{NewRect(0, 0, 2, 2), NewRect(1, 1, 2, 2)},
+            []Rect{NewRect(0, 0, 2, 2), NewRect(1, 1, 2, 2)}},
+        {"duplicate dropped", []Rect{NewRect(2, 2, 1, 1), NewRect(2, 2, 1, 1)},
+            []Rect{NewRect(2, 2, 1, 1)}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            env := NewEnvironment(nil, UNLIMITED_CAPACITY, 0)
+            for _, r := range tc.added {
+                env.addDamage(r)
+            }
+
+            if !slices.Equal(env.damage, tc.expected) {
+                t.Errorf("expected %v, got %v", tc.expected, env.damage)
+            }
+        })
+    }
+}
+
+// Past maxDamageRects, every region is merged into one.
+func TestAddDamageMerges(t *testing.T) {
+    env := NewEnvironment(nil, UNLIMITED_CAPACITY, 0)
+
+    for i := 0; i < maxDamageRects; i++ {
+        env.addDamage(NewRect(i * 2, 0, 1, 1))
+    }
+
+    if len(env.damage) != maxDamageRects {
+        t.Fatalf("expected %d regions, got %d", maxDamageRects, len(env.damage))
+    }
+
+    env.addDamage(NewRect(0, 9, 1, 1))
+
+    expected := []Rect{NewRect(0, 0, maxDamageRects * 2 - 1, 10)}
+    if !slices.Equal(env.damage, expected) {
+        t.Errorf("expected %v, got %v", expected, env.damage)
+    }
+
+    if !env.damaged(NewRect(1, 5, 1, 1)) || env.damaged(NewRect(100, 0, 1, 1)) {
+        t.Error("damaged is wrong")
+    }
+}
@@ -0,0 +1,289 @@
+package tui
+
+import (
+	"fmt"
+)
+
+// A division spec describes how much space a single division should 
+// receive when some total dimmension is split up between divisions.
+// Division specs are used by the divided element.
+//
+// Every spec resolves to a set of bounds. The allocator first gives each
+// division its basis. If there is space left over, it is handed out to 
+// flexible divisions (in proportion to their flex factors) without growing 
+// any division past its max. If there is not enough space, divisions shrink
+// towards their mins (in proportion to how far they are able to shrink).
+type DivisionSpec interface {
+    // totalDim is the size of the space being divided.
+    // pref is the preferred size of the division's element.
+    // (See Element.Measure)
+    Resolve(totalDim int, pref int) DivisionBounds
+}
+
+// When a division's max is UNBOUNDED, it can grow indefinitely.
+const UNBOUNDED = -1
+
+type DivisionBounds struct {
+    basis int
+    min int
+    max int
+    flexFactor int
+}
+
+// Fixed divisions request an exact size.
+// When space runs out, they may shrink.
+type FixedSpec struct {
+    fixedSize int
+}
+
+func NewFixedSpec(size int) FixedSpec {
+    return FixedSpec{
+        fixedSize: size,
+    }
+}
+
+func (fs FixedSpec) Resolve(totalDim int, pref int) DivisionBounds {
+    return DivisionBounds{
+        basis: fs.fixedSize,
+        min: 0,
+        max: fs.fixedSize,
+        flexFactor: 0,
+    }
+}
+
+// Flex divisions share leftover space.
+// A flex division can optionally be clamped between a min and a max.
+type FlexSpec struct {
+    flexFactor int
+
+    min int
+    max int
+}
+
+func NewFlexSpec(factor int) FlexSpec {
+    return FlexSpec{
+        flexFactor: factor,
+        min: 0,
+        max: UNBOUNDED,
+    }
+}
+
+func NewBoundedFlexSpec(factor int, min int, max int) FlexSpec {
+    return FlexSpec{
+        flexFactor: factor,
+        min: min,
+        max: max,
+    }
+}
+
+func (fs FlexSpec) Resolve(totalDim int, pref int) DivisionBounds {
+    return DivisionBounds{
+        basis: fs.min,
+        min: fs.min,
+        max: fs.max,
+        flexFactor: fs.flexFactor,
+    }
+}
+
+// Percent divisions request a percentage of the total space being divided.
+// (Rounded down)
+type PercentSpec struct {
+    percent int
+}
+
+func NewPercentSpec(p int) PercentSpec {
+    return PercentSpec{
+        percent: p,
+    }
+}
+
+func (ps PercentSpec) Resolve(totalDim int, pref int) DivisionBounds {
+    size := (totalDim * ps.percent) / 100
+
+    return DivisionBounds{
+        basis: size,
+        min: 0,
+        max: size,
+        flexFactor: 0,
+    }
+}
+
+// Auto divisions request the preferred size of their element, clamped 
+// between a min and a max.
+type AutoSpec struct {
+    min int
+    max int
+}
+
+func NewAutoSpec(min int, max int) AutoSpec {
+    return AutoSpec{
+        min: min,
+        max: max,
+    }
+}
+
+func (as AutoSpec) Resolve(totalDim int, pref int) DivisionBounds {
+    size := max(pref, as.min)
+    if as.max != UNBOUNDED {
+        size = min(size, as.max)
+    }
+
+    return DivisionBounds{
+        basis: size,
+        min: as.min,
+        max: size,
+        flexFactor: 0,
+    }
+}
+
+// Splits amount into integer shares proportional to weights.
+// No share will exceed its corresponding cap. (UNBOUNDED = no cap)
+// Units lost to rounding are given to the shares with the largest
+// remainders. (Ties go to the earlier share)
+//
+// If every share reaches its cap, the amount which could not be 
+// distributed is simply not given out.
+func distribute(amount int, weights []int, caps []int) []int {
+    shares := make([]int, len(weights))
+
+    active := make([]bool, len(weights))
+    for i, w := range weights {
+        active[i] = w > 0 && caps[i] != 0
+    }
+
+    for amount > 0 {
+        totalWeight := 0
+        for i, w := range weights {
+            if active[i] {
+                totalWeight += w
+            }
+        }
+
+        if totalWeight == 0 {
+            break
+        }
+
+        // If any share hits its cap, freeze it and start again
+        // with what's left.
+        capped := false
+        for i, w := range weights {
+            if !active[i] || caps[i] == UNBOUNDED {
+                continue
+            }
+
+            if (amount * w) / totalWeight >= caps[i] {
+                shares[i] = caps[i]
+                amount -= caps[i]
+                active[i] = false
+                capped = true
+            }
+        }
+
+        if capped {
+            continue
+        }
+
+        remainders := make([]int, len(weights))
+        given := 0
+        for i, w := range weights {
+            if active[i] {
+                shares[i] = (amount * w) / totalWeight
+                remainders[i] = (amount * w) % totalWeight
+                given += shares[i]
+            }
+        }
+
+        // Hand out rounding leftovers one unit at a time.
+        // Since no share was capped above, each share has room for
+        // one more unit.
+        for leftover := amount - given; leftover > 0; leftover-- {
+            best := -1
+            for i := range weights {
+                if active[i] && (best == -1 || remainders[i] > remainders[best]) {
+                    best = i
+                }
+            }
+
+            shares[best]++
+            remainders[best] = -1
+        }
+
+        break
+    }
+
+    return shares
+}
+
+// Helper function for calculating dimmensions of child elements.
+// prefs holds the preferred size of each division's element.
+func mapToDims(totalDim int, specs []DivisionSpec, prefs []int) ([]int, error) { 
+    bounds := make([]DivisionBounds, len(specs))
+
+    totalBasis := 0
+    for i, spec := range specs {
+        b := spec.Resolve(totalDim, prefs[i])
+
+        if b.min < 0 || b.basis < b.min || b.flexFactor < 0 {
+            return nil, fmt.Errorf("mapToDims: Bad division bounds: %d", i)
+        }
+
+        if b.max != UNBOUNDED && b.max < b.basis {
+            return nil, fmt.Errorf("mapToDims: Division max below basis: %d", i)
+        }
+
+        bounds[i] = b
+        totalBasis += b.basis
+    }
+
+    dims := make([]int, len(specs)) 
+    for i, b := range bounds {
+        dims[i] = b.basis
+    }
+
+    weights := make([]int, len(specs))
+    caps := make([]int, len(specs))
+
+    if totalBasis <= totalDim {
+        // There is space left over, grow the flexible divisions.
+        // If there are no flexible divisions, this area will be left blank.
+        for i, b := range bounds {
+            weights[i] = b.flexFactor
+            caps[i] = b.max
+            if caps[i] != UNBOUNDED {
+                caps[i] -= b.basis
+            }
+        }
+
+        growth := distribute(totalDim - totalBasis, weights, caps)
+        for i := range dims {
+            dims[i] += growth[i]
+        }
+
+        return dims, nil
+    }
+
+    // Otherwise, we must shrink.
+    // Each division shrinks in proportion to how much it is able to shrink.
+    totalShrinkable := 0
+    for i, b := range bounds {
+        weights[i] = b.basis - b.min
+        caps[i] = weights[i]
+        totalShrinkable += weights[i]
+    }
+
+    deficit := totalBasis - totalDim
+    shrinkage := distribute(min(deficit, totalShrinkable), weights, caps)
+    for i := range dims {
+        dims[i] -= shrinkage[i]
+    }
+
+    // NOTE: If even the mins don't fit, divisions are cut off
+    // starting from the end.
+    pos := 0
+    for i := range dims {
+        dims[i] = min(dims[i], totalDim - pos)
+        pos += dims[i]
+    }
+
+    return dims, nil
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDistribute(t *testing.T) {
+    cases := []struct {
+        name string
+        amount int
+        weights []int
+        caps []int
+        expected []int
+    }{
+        {"even", 10, []int{1, 1}, []int{UNBOUNDED, UNBOUNDED}, []int{5, 5}},
+        {"proportional", 9, []int{1, 2}, []int{UNBOUNDED, UNBOUNDED}, []int{3, 6}},
+        {"remainder to largest", 10, []int{1, 2}, []int{UNBOUNDED, UNBOUNDED}, []int{3, 7}},
+        {"remainder tie to earlier", 5, []int{1, 1}, []int{UNBOUNDED, UNBOUNDED}, []int{3, 2}},
+        {"capped share frees space", 10, []int{1, 1}, []int{2, UNBOUNDED}, []int{2, 8}},
+        {"every share capped", 10, []int{1, 1}, []int{2, 3}, []int{2, 3}},
+        {"zero weight", 6, []int{0, 1}, []int{UNBOUNDED, UNBOUNDED}, []int{0, 6}},
+        {"zero cap", 6, []int{1, 1}, []int{0, UNBOUNDED}, []int{0, 6}},
+        {"nothing to give", 0, []int{1, 1}, []int{UNBOUNDED, UNBOUNDED}, []int{0, 0}},
+        {"no shares", 4, []int{}, []int{}, []int{}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            actual := distribute(tc.amount, tc.weights, tc.caps)
+            if !slices.Equal(actual, tc.expected) {
+                t.Errorf("expected %v, got %v", tc.expected, actual)
+            }
+        })
+    }
+}
+
+func TestMapToDims(t *testing.T) {
+    cases := []struct {
+        name string
+        totalDim int
+        specs []DivisionSpec
+        prefs []int
+        expected []int
+    }{
+        // Growing.
+        {"fixed exact", 10, []DivisionSpec{NewFixedSpec(4), NewFixedSpec(6)},
+            []int{0, 0}, []int{4, 6}},
+        {"fixed leaves space blank", 10, []DivisionSpec{NewFixedSpec(2), NewFixedSpec(3)},
+            []int{0, 0}, []int{2, 3}},
+        {"flex shares leftover", 12, []DivisionSpec{NewFixedSpec(3), NewFlexSpec(1), NewFlexSpec(2)},
+            []int{0, 0, 0}, []int{3, 3, 6}},
+        {"bounded flex max", 20, []DivisionSpec{NewBoundedFlexSpec(1, 0, 4), NewFlexSpec(1)},
+            []int{0, 0}, []int{4, 16}},
+        {"bounded flex min", 4, []DivisionSpec{NewBoundedFlexSpec(1, 3, UNBOUNDED), NewFlexSpec(1)},
+            []int{0, 0}, []int{4, 0}},
+        {"percent", 10, []DivisionSpec{NewPercentSpec(25), NewPercentSpec(50)},
+            []int{0, 0}, []int{2, 5}},
+        {"auto uses pref", 10, []DivisionSpec{NewAutoSpec(0, UNBOUNDED), NewFlexSpec(1)},
+            []int{4, 0}, []int{4, 6}},
+        {"auto clamped", 10, []DivisionSpec{NewAutoSpec(2, 3), NewAutoSpec(2, 3)},
+            []int{8, 0}, []int{3, 2}},
+
+        // Shrinking.
+        {"shrink proportionally", 9, []DivisionSpec{NewFixedSpec(8), NewFixedSpec(4)},
+            []int{0, 0}, []int{6, 3}},
+        {"shrink by shrinkable amount", 7, []DivisionSpec{NewAutoSpec(4, UNBOUNDED), NewFixedSpec(5)},
+            []int{6, 0}, []int{5, 2}},
+        {"flex shrinks first", 5, []DivisionSpec{NewFixedSpec(5), NewFlexSpec(1)},
+            []int{0, 0}, []int{5, 0}},
+
+        // Not even the mins fit, so the shrinkable deficit is less than the
+        // whole deficit. The last divisions are cut off.
+        {"mins cut off", 5, []DivisionSpec{NewAutoSpec(3, UNBOUNDED), NewAutoSpec(4, UNBOUNDED)},
+            []int{6, 6}, []int{3, 2}},
+        {"mins cut off entirely", 2, []DivisionSpec{NewAutoSpec(3, UNBOUNDED), NewFixedSpec(2)},
+            []int{3, 0}, []int{2, 0}},
+        {"no space", 0, []DivisionSpec{NewFixedSpec(2), NewFlexSpec(1)},
+            []int{0, 0}, []int{0, 0}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            actual, err := mapToDims(tc.totalDim, tc.specs, tc.prefs)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            if !slices.Equal(actual, tc.expected) {
+                t.Errorf("expected %v, got %v", tc.expected, actual)
+            }
+        })
+    }
+}
+
+func TestMapToDimsBadBounds(t *testing.T) {
+    cases := []struct {
+        name string
+        specs []DivisionSpec
+    }{
+        {"negative min", []DivisionSpec{NewBoundedFlexSpec(1, -1, UNBOUNDED)}},
+        {"max below min", []DivisionSpec{NewBoundedFlexSpec(1, 4, 2)}},
+        {"negative factor", []DivisionSpec{NewFlexSpec(-1)}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            _, err := mapToDims(10, tc.specs, make([]int, len(tc.specs)))
+            if err == nil {
+                t.Error("expected an error")
+            }
+        })
+    }
+}
@@ -7,18 +7,6 @@ import (
 )
 
 
-type Viewport struct {
-    // Coordinates of where the viewport should be 
-    // rendered on screen.
-    x, y int
-
-    // Offset into the element which is being rendered.
-    xOff, yOff int
-
-    // Dimmensions of the viewport.
-    width, height int
-}
-
 // An element factory is meant to create an element then register
 // it into the environment.
 // Returning the element's new ID.
@@ -37,25 +25,30 @@ type Element interface {
     // element alone, NOT its children.
     Start()
 
-    // This should resize the given element.
+    // Layout happens in two passes. (See Environment.Layout)
     //
+    // 1) Measure: A parent asks its child how much space it would like
+    //    given the space which is available. Measuring should have no side
+    //    effects. Containers usually measure their children to answer.
+    //
+    // 2) Arrange: A parent assigns its child a rectangle through Resize.
+    //    The child is not required to fill the rectangle, but it must not 
+    //    draw outside of it.
 
-    // Every element will have a width and height.
-    // SetWidth/Height may throw errors, since some elements may not allow
-    // for such operations.
-    // For example an element which grows downward based on the elements it
-    // contains may not allow for the user to randomly set its height.
-    // Using SetWidth/Height always triggers the draw flag (If there is no error that is)
-
-    // NOTE: The below 3 Set calls will all automatically trigger a redraw for this
-    // element. They SHOULD be recursive, if child elements must also be resized somehow.
-
-    GetWidth(ectx *ElementContext) int
-    SetWidth(ectx *ElementContext, w int) error
+    Measure(ectx *ElementContext, cons Constraints) SizeHint
 
-    GetHeight(ectx *ElementContext) int
-    SetHeight(ectx *ElementContext, h int) error
+    // This should resize the given element. (Arrange)
+    //
+    // NOTE: if this is a container element, a resize should be
+    // called recursively for child elements.
+    // 
+    // No need to set the draw flag in this function, this is automatically
+    // handled by the environment when a resize is forwarded.
+    Resize(ectx *ElementContext, r, c int, rows, cols int) error
 
+    // Called when the environment gives this element a new viewport.
+    // (See Environment.SetViewport)
+    // An element may return an error if it cannot be viewed this way.
     SetViewport(ectx *ElementContext, vp Viewport) error
 
     // All non-resize events are forwarded through this call.
@@ -69,14 +62,16 @@ type Element interface {
     GetDrawFlag() bool
 
     // This always draws just THIS element. Nothing recursive here.
-    // If Drawing this element requires the redrawing of children or vice versa,
-    // make sure to declare this explicitly in handle event somewhere.
+    // The given canvas covers exactly this element's rectangle. (See canvas.go)
+    // Children which this element draws over are redrawn automatically.
+    // (See damage.go) Drawing may be clipped, so an element should draw
+    // its entire area every time.
     //
     // NOTE: Do not mess with the draw flag here... that is handled entirely
     // by the environment.
     // 
     // NOTE: See environment.Draw.
-    Draw(s tcell.Screen)
+    Draw(cv *Canvas)
 
     // When an element is deregistered, stop is called.
     //
@@ -86,6 +81,21 @@ type Element interface {
     Stop()
 }
 
+// The space available to an element during the measure phase.
+type Constraints struct {
+    MaxRows, MaxCols int
+}
+
+// What an element reports during the measure phase.
+// Max values can be UNBOUNDED.
+//
+// NOTE: The environment clamps the preferred size to the given constraints.
+type SizeHint struct {
+    PrefRows, PrefCols int
+    MinRows, MinCols int
+    MaxRows, MaxCols int
+}
+
 type DefaultElement struct {
     r, c int
     rows, cols int
@@ -118,6 +128,24 @@ func (de *DefaultElement) Resize(ectx *ElementContext, r, c int, rows, cols int)
     return nil
 }
 
+// By default, an element would like no space at all, but will
+// take any amount of space it is given.
+func (de *DefaultElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    return SizeHint{
+        PrefRows: 0,
+        PrefCols: 0,
+        MinRows: 0,
+        MinCols: 0,
+        MaxRows: UNBOUNDED,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+// By default, elements can be placed inside any viewport.
+func (de *DefaultElement) SetViewport(ectx *ElementContext, vp Viewport) error {
+    return nil
+}
+
 func (de *DefaultElement) GetR() int {
     return de.r
 }
@@ -146,12 +174,8 @@ func (de *DefaultElement) GetDrawFlag() bool {
     return de.drawFlag
 }
 
-func (de *DefaultElement) Draw(s tcell.Screen) {
-    for i := de.r; i < de.r + de.rows; i++ {
-        for j := de.c; j < de.c + de.cols; j++ {
-            s.SetContent(j, i, ' ', nil, tcell.StyleDefault)
-        }
-    }
+func (de *DefaultElement) Draw(cv *Canvas) {
+    cv.Fill(' ', tcell.StyleDefault)
 }
 
 func (de *DefaultElement) Stop() {
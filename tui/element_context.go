@@ -3,6 +3,7 @@ package tui
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -14,6 +15,31 @@ type ElementContext struct {
     selfID ElementID 
 
     children []ChildContext
+
+    // The rectangle this element was last resized to.
+    // (In the coordinate space of its viewport, if it has one)
+    r, c int
+    rows, cols int
+
+    // When non-nil, this element and its descendants are drawn
+    // through this viewport. (See Environment.SetViewport)
+    viewport *Viewport
+
+    // A hidden element (and its descendants) is not drawn, can't be
+    // hit by the mouse and can't be focused. (See Environment.SetHidden)
+    hidden bool
+
+    // Where this element appeared on screen during the last draw.
+    // (See damage.go)
+    drawnRect Rect
+
+    // Timers belonging to this element. (See timer.go)
+    timers []*Timer
+
+    // Cached measurement. (See Environment.Measure)
+    measureGen int
+    measureCons Constraints
+    measureHint SizeHint
 }
 
 type ChildContext struct {
@@ -95,40 +121,147 @@ func (ectx *ElementContext) NumChildren() int {
     return len(ectx.children)
 }
 
-func (ectx *ElementContext) RequestExit() {
-    ectx.env.RequestExit()
+// Returns a function which can be called from any goroutine to dispatch an
+// event to this element. (See Environment.PostEvent)
+func (ectx *ElementContext) EventPoster() func (tcell.Event) {
+    env := ectx.env
+    eid := ectx.selfID
+
+    return func (ev tcell.Event) {
+        env.PostEvent(eid, ev)
+    }
+}
+
+func (ectx *ElementContext) After(d time.Duration, fn func () error) (*Timer, error) {
+    return ectx.env.After(ectx.selfID, d, fn)
+}
+
+func (ectx *ElementContext) Every(d time.Duration, fn func () error) (*Timer, error) {
+    return ectx.env.Every(ectx.selfID, d, fn)
 }
 
-func (ectx *ElementContext) GetWidth() int {
-    width, _ := ectx.env.GetWidth(ectx.selfID)
-    return width
+func (ectx *ElementContext) Clock() time.Duration {
+    return ectx.env.Clock()
+}
+
+func (ectx *ElementContext) RequestExit() {
+    ectx.env.RequestExit()
 }
 
-func (ectx *ElementContext) ForwardNewWidth(w int) error {
-    return ectx.env.SetWidth(ectx.selfID, w)
+// Measure this element. (See Environment.Measure)
+func (ectx *ElementContext) Measure(cons Constraints) SizeHint {
+    // This should never error as selfID is a valid ID.
+    hint, _ := ectx.env.Measure(ectx.selfID, cons)
+    return hint
 }
 
-func (ectx *ElementContext) GetHeight() int {
-    height, _ := ectx.env.GetHeight(ectx.selfID)
-    return height
+func (ectx *ElementContext) RequestLayout() {
+    ectx.env.RequestLayout()
 }
 
-func (ectx *ElementContext) ForwardNewHeight(h int) error {
-    return ectx.env.SetHeight(ectx.selfID, h)
+func (ectx *ElementContext) ForwardResize(r, c int, rows, cols int) error {
+    return ectx.env.ForwardResize(ectx.selfID, r, c, rows, cols)
 }
 
 func (ectx *ElementContext) SetViewport(vp Viewport) error {
     return ectx.env.SetViewport(ectx.selfID, vp)
 }
 
+func (ectx *ElementContext) ClearViewport() error {
+    return ectx.env.ClearViewport(ectx.selfID)
+}
+
 func (ectx *ElementContext) ForwardEvent(ev tcell.Event) error {
     return ectx.env.ForwardEvent(ectx.selfID, ev)
 }
 
+// Dispatch an event with this element as the target. (See Dispatch)
+func (ectx *ElementContext) Dispatch(ev tcell.Event) (bool, error) {
+    return ectx.env.Dispatch(ectx.selfID, ev)
+}
+
+// The below calls refer to the event currently being handled.
+
+func (ectx *ElementContext) Phase() EventPhase {
+    ds := ectx.env.currDispatch()
+    if ds == nil {
+        return PHASE_NONE
+    }
+
+    return ds.phase
+}
+
+func (ectx *ElementContext) EventTarget() ElementID {
+    ds := ectx.env.currDispatch()
+    if ds == nil {
+        return NULL_EID
+    }
+
+    return ds.target
+}
+
+// No elements after this one will receive the event.
+func (ectx *ElementContext) StopPropagation() {
+    ds := ectx.env.currDispatch()
+    if ds != nil {
+        ds.stopped = true
+    }
+}
+
+// Marks the event as handled. Elements later in the propagation
+// should usually ignore handled events.
+func (ectx *ElementContext) MarkHandled() {
+    ds := ectx.env.currDispatch()
+    if ds != nil {
+        ds.handled = true
+    }
+}
+
+func (ectx *ElementContext) EventHandled() bool {
+    ds := ectx.env.currDispatch()
+    return ds != nil && ds.handled
+}
+
 func (ectx *ElementContext) SetDrawFlag() {
     ectx.env.SetDrawFlag(ectx.selfID)
 }
 
+func (ectx *ElementContext) SetDrawFlagTree() {
+    ectx.env.SetDrawFlagTree(ectx.selfID)
+}
+
+func (ectx *ElementContext) Focus() error {
+    return ectx.env.Focus(ectx.selfID)
+}
+
+// Asks for the terminal cursor to be shown at cell (r, c) of this element.
+// (See cursor.go)
+func (ectx *ElementContext) ShowCursor(r, c int) {
+    // This should never error as selfID is a valid ID.
+    ectx.env.ShowCursor(ectx.selfID, r, c)
+}
+
+func (ectx *ElementContext) HideCursor() {
+    ectx.env.HideCursor(ectx.selfID)
+}
+
+func (ectx *ElementContext) IsFocused() bool {
+    return ectx.env.FocusedID() == ectx.selfID
+}
+
+// Returns true if this element or one of its descendants has focus.
+func (ectx *ElementContext) FocusWithin() bool {
+    return ectx.env.focusWithin(ectx.selfID)
+}
+
+func (ectx *ElementContext) SetHidden(h bool) error {
+    return ectx.env.SetHidden(ectx.selfID, h)
+}
+
+func (ectx *ElementContext) IsHidden() bool {
+    return ectx.hidden
+}
+
 func (ectx *ElementContext) DetachAndDeregister() error {
     err := ectx.env.Detach(ectx.selfID)
     if err != nil {
@@ -138,6 +271,33 @@ func (ectx *ElementContext) DetachAndDeregister() error {
     return ectx.env.Deregister(ectx.selfID)
 }
 
+// Creates and registers a new element, then pushes it as a layer.
+// (See Environment.PushLayer)
+func (ectx *ElementContext) CreateAndPushLayer(f ElementFactory, spec LayerSpec) (ElementID, error) {
+    eid, err := ectx.env.CreateAndRegister(f)
+    if err != nil {
+        return NULL_EID, err
+    }
+
+    err = ectx.env.PushLayer(eid, spec)
+    if err != nil {
+        ectx.env.Deregister(eid)
+        return NULL_EID, err
+    }
+
+    return eid, nil
+}
+
+// Used by the root of a layer to close itself.
+func (ectx *ElementContext) RemoveLayerAndDeregister() error {
+    err := ectx.env.RemoveLayer(ectx.selfID)
+    if err != nil {
+        return err
+    }
+
+    return ectx.env.Deregister(ectx.selfID)
+}
+
 
 
 
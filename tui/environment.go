@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
+    "sync"
     "time"
 )
 
@@ -13,11 +15,41 @@ import (
 //
 // NOTE: By design an environment is in no way thread-safe.
 // All actions on an environment are supposed to be synchronous and
-// non-blocking.
+// non-blocking. The one exception is posting. (See post.go)
 
-type ElementID int
+// An element ID refers to a single element for as long as it is registered.
+//
+// The low 32 bits of an ID hold the slot of the element in the environment.
+// The remaining bits hold the slot's generation. A slot's generation 
+// increases every time an element is deregistered from it, this way an ID 
+// kept after its element is deregistered never refers to a different element.
+type ElementID int64
 const NULL_EID = -1
 
+const slotBits = 32
+const slotMask = (1 << slotBits) - 1
+
+func makeElementID(slot int, gen int) ElementID {
+    return ElementID((int64(gen) << slotBits) | int64(slot))
+}
+
+func (eid ElementID) slot() int {
+    return int(int64(eid) & slotMask)
+}
+
+func (eid ElementID) generation() int {
+    return int(int64(eid) >> slotBits)
+}
+
+// getEnvEntry errors wrap one of these, they can be checked with errors.Is.
+var (
+    // The ID was never given out by this environment.
+    ErrUnknownElementID = errors.New("unknown element id")
+
+    // The ID's element has been deregistered.
+    ErrStaleElementID = errors.New("stale element id")
+)
+
 type EnvEntry struct {
     ectx *ElementContext
     e Element
@@ -27,22 +59,53 @@ type Environment struct {
     // Map containing all elements in the entire environment.
     elements []*EnvEntry
 
+    // The current generation of each slot in the elements map.
+    generations []int
+
     // Size of the elements map will never outgrow maxCapacity.
+    // This can be UNLIMITED_CAPACITY.
     maxCapacity int
 
     // The number of non-nil entries in the map.
     fill int
 
-    // This simply an arbitrary id value, we start our search
-    // at this value when looking for a new ID.
-    // If fill < len(elements) it is gauranteed that ptrID
-    // is a valid index into the elements map.
+    // Slots of the elements map which are nil. Used as a stack, so
+    // the most recently freed slot is reused first.
     //
-    // NOTE: see Register.
-    ptrID ElementID
+    // NOTE: see Register and Deregister.
+    freeSlots []int
 
     rootID ElementID 
 
+    // Trees drawn above the root. (See layer.go)
+    layers []*layer
+
+    // Where the cursor was asked to be, and where it was last placed
+    // on screen. (See cursor.go)
+    cursor cursorState
+    placedCursor cursorState
+
+    // The element which receives key events. (See focus.go)
+    focusID ElementID
+
+    // The element under the mouse, and the element which has captured 
+    // the mouse. (See mouse.go)
+    hoverID ElementID
+    captureID ElementID
+
+    // Stack of dispatches in progress. (See propagation.go)
+    dispatches []*dispatchState
+
+    // Work posted from other goroutines. (See post.go)
+    // postMu must be held when accessing posted.
+    postMu sync.Mutex
+    posted []func (*Environment) error
+
+    // The environment's clock advances by updateDur every tick.
+    // Timers are scheduled using this clock. (See timer.go)
+    clock time.Duration
+    timers timerHeap
+
     // The screen this Environment draws to.
     screen tcell.Screen
 
@@ -53,18 +116,58 @@ type Environment struct {
     // When this is set to true, the environment will exit its
     // run call this cycle.
     exitRequested bool
+
+    // Screen regions which must be redrawn during the next draw.
+    // (See damage.go)
+    damage []Rect
+
+    // When this is set to true, the environment will perform a layout
+    // before drawing this cycle.
+    layoutRequested bool
+
+    // Incremented at the start of every layout pass.
+    // Measurements are only cached while layingOut is true.
+    layoutGen int
+    layingOut bool
 }
 
+// Pass this as the max capacity of an environment to let its
+// elements map grow as needed.
+const UNLIMITED_CAPACITY = -1
+
+// The elements map starts with room for this many slots.
+const initialSlots = 16
+
+// The most slots an environment can ever have. (See ElementID)
+const maxSlots = 1 << slotBits
+
+// mc is the most elements which can be registered at once, it can be
+// UNLIMITED_CAPACITY.
 func NewEnvironment(s tcell.Screen, mc int, ud time.Duration) *Environment {
     return &Environment{
-        elements: make([]*EnvEntry, 10),
+        elements: make([]*EnvEntry, 0, initialSlots),
+        generations: make([]int, 0, initialSlots),
         maxCapacity: mc,
         fill: 0,
-        ptrID: 0,
+        freeSlots: make([]int, 0),
         rootID: NULL_EID,
+        layers: make([]*layer, 0),
+        cursor: cursorState{eid: NULL_EID},
+        placedCursor: cursorState{eid: NULL_EID},
+        focusID: NULL_EID,
+        hoverID: NULL_EID,
+        captureID: NULL_EID,
+        dispatches: make([]*dispatchState, 0),
+        posted: nil,
+        clock: 0,
+        timers: make(timerHeap, 0),
         screen: s,
         updateDur: ud,
         exitRequested: false,
+        damage: make([]Rect, 0),
+        layoutRequested: false,
+        layoutGen: 0,
+        layingOut: false,
     }
 }
 
@@ -85,26 +188,26 @@ func (env *Environment) Register(e Element) (ElementID, error) {
         return -1, fmt.Errorf("Register: Environment at max capacity: %d", env.maxCapacity)
     }
 
-    var eid ElementID
+    var slot int
 
-    if env.fill == len(env.elements) {
-        // In this case our elements map is full, but not yet at
-        // it's max capcity. Add an extra spot, make that the id.
-        eid = ElementID(len(env.elements))
-        env.elements = append(env.elements, nil)
+    if len(env.freeSlots) > 0 {
+        // Reuse the most recently freed slot.
+        slot = env.freeSlots[len(env.freeSlots) - 1]
+        env.freeSlots = env.freeSlots[:len(env.freeSlots) - 1]
     } else {
-        // Otherwise, there is a spot in the map, we just need to 
-        // find it.
-        for ; env.elements[env.ptrID] != nil; env.ptrID++ {
-            if int(env.ptrID) == len(env.elements) {
-                env.ptrID = -1  // Will be zero of post action.
-            }
+        // Otherwise, every slot is in use. Add an extra one.
+        if len(env.elements) == maxSlots {
+            return -1, fmt.Errorf("Register: Environment out of slots: %d", maxSlots)
         }
 
-        eid = env.ptrID
+        slot = len(env.elements)
+        env.elements = append(env.elements, nil)
+        env.generations = append(env.generations, 0)
     }
 
-    env.elements[eid] = &EnvEntry{
+    eid := makeElementID(slot, env.generations[slot])
+
+    env.elements[slot] = &EnvEntry{
         ectx: &ElementContext{
             env: env,
             parentID: NULL_EID,
@@ -126,18 +229,35 @@ func (env *Environment) RequestExit() {
 }
 
 func (env *Environment) getEnvEntry(eid ElementID) (*EnvEntry, error) {
-    if eid < 0 || len(env.elements) <= int(eid) {
-        return nil, fmt.Errorf("getEnvEntry: Out of bounds element id: %d", eid)
+    slot := eid.slot()
+
+    if eid < 0 || len(env.elements) <= slot {
+        return nil, fmt.Errorf("getEnvEntry: %w: %d", ErrUnknownElementID, eid)
+    }
+
+    gen := eid.generation()
+    if gen > env.generations[slot] {
+        return nil, fmt.Errorf("getEnvEntry: %w: %d", ErrUnknownElementID, eid)
     }
 
-    ee := env.elements[eid] 
+    if gen < env.generations[slot] {
+        return nil, fmt.Errorf("getEnvEntry: %w: %d", ErrStaleElementID, eid)
+    }
+
+    // The slot's current generation has not been given out yet.
+    ee := env.elements[slot] 
     if ee == nil {
-        return nil, fmt.Errorf("getEnvEntry: Unknown element id: %d", eid)
+        return nil, fmt.Errorf("getEnvEntry: %w: %d", ErrUnknownElementID, eid)
     }
 
     return ee, nil
 }
 
+// Returns the entry of an ID which is known to be valid.
+func (env *Environment) entry(eid ElementID) *EnvEntry {
+    return env.elements[eid.slot()]
+}
+
 func (env *Environment) GetElementContext(eid ElementID) (*ElementContext, error) {
     ee, err := env.getEnvEntry(eid)
     if err != nil {
@@ -174,6 +294,10 @@ func (env *Environment) attach(pid ElementID, eid ElementID, at bool, index int)
             ectx.parentID, eid)
     }
 
+    if env.layerIndex(eid) != -1 {
+        return 0, fmt.Errorf("attach: Element is a layer: %d", eid)
+    }
+
     pe, err := env.getEnvEntry(pid)
     if err != nil {
         return 0, fmt.Errorf("attach: %w", err)
@@ -228,13 +352,16 @@ func (env *Environment) Detach(eid ElementID) error {
 
     // Perform detach!
 
+    // Whatever the element covered must be drawn again.
+    env.damageTree(eid)
+
     ectx.parentID = NULL_EID 
 
     // Our element has no parent pointer now.
     // Now are old parent must have no record of our
     // element.
 
-    pctx := env.elements[pid].ectx
+    pctx := env.entry(pid).ectx
     parentChildren := pctx.children
 
     // eid must be in the child array of parent.
@@ -252,7 +379,7 @@ func (env *Environment) MakeRoot(eid ElementID) error {
     // Clearing the root always works!
     if eid == NULL_EID {
         env.rootID = eid
-        return nil
+        return env.Blur()
     }
 
     if env.rootID == eid {
@@ -268,12 +395,26 @@ func (env *Environment) MakeRoot(eid ElementID) error {
         return fmt.Errorf("MakeRoot: Element has parent: %d, %d", ee.ectx.parentID, eid)
     }
 
+    if env.layerIndex(eid) != -1 {
+        return fmt.Errorf("MakeRoot: Element is a layer: %d", eid)
+    }
+
     env.rootID = eid
 
-    // When something is made a root, it must be resized to fit the current
+    // Focus cannot stay outside of the root's tree.
+    if env.focusID != NULL_EID && !env.inTree(env.focusID) {
+        err = env.Blur()
+        if err != nil {
+            return fmt.Errorf("MakeRoot: %w", err)
+        }
+    }
+
+    // When something is made a root, it must be laid out to fit the current
     // screen.
-    cols, rows := env.screen.Size() 
-    err = env.ForwardResize(env.rootID, 0, 0, rows, cols)
+    err = env.Layout()
+    if err != nil {
+        return fmt.Errorf("MakeRoot: %w", err)
+    }
 
     return nil
 }
@@ -332,56 +473,106 @@ func (env *Environment) DeleteChildAttr(eid ElementID, childIndex int, key strin
 
 // Sizing Stuff.
 
-func (env *Environment) GetWidth(eid ElementID) (int, error) {
+// Measure asks the given element how much space it would like given
+// some constraints. (See Element.Measure)
+//
+// During a layout pass, measurements are cached. This way, containers can
+// measure their children during both the measure and arrange phases without
+// the work growing with the depth of the tree.
+func (env *Environment) Measure(eid ElementID, cons Constraints) (SizeHint, error) {
     ee, err := env.getEnvEntry(eid)
     if err != nil {
-        return 0, fmt.Errorf("GetWidth: %w", err)
+        return SizeHint{}, fmt.Errorf("Measure: %w", err)
     }
+    ectx := ee.ectx
 
-    return ee.e.GetWidth(ee.ectx), nil
-}
-
-func (env *Environment) SetWidth(eid ElementID, w int) error {
-    ee, err := env.getEnvEntry(eid)
-    if err != nil {
-        return fmt.Errorf("SetWidth: %w", err)
+    if env.layingOut && ectx.measureGen == env.layoutGen && ectx.measureCons == cons {
+        return ectx.measureHint, nil
     }
 
-    err = ee.e.SetWidth(ee.ectx, w)
+    hint := ee.e.Measure(ectx, cons)
+    hint.PrefRows = max(min(hint.PrefRows, cons.MaxRows), 0)
+    hint.PrefCols = max(min(hint.PrefCols, cons.MaxCols), 0)
 
-    if err != nil {
-        return fmt.Errorf("SetWidth: %w", err)
+    if env.layingOut {
+        ectx.measureGen = env.layoutGen
+        ectx.measureCons = cons
+        ectx.measureHint = hint
     }
 
-    ee.e.SetDrawFlag(true)
-    return nil
+    return hint, nil
 }
 
-func (env *Environment) GetHeight(eid ElementID) (int, error) {
-    ee, err := env.getEnvEntry(eid)
+// Layout measures then arranges the whole tree starting at the root.
+// The root is always given the entire screen.
+// Layers are laid out afterwards. (See layer.go)
+func (env *Environment) Layout() error {
+    env.layoutRequested = false
+
+    env.layingOut = true
+    env.layoutGen++
+    defer func() {
+        env.layingOut = false
+    }()
+
+    if env.rootID != NULL_EID {
+        cols, rows := env.screen.Size() 
+
+        _, err := env.Measure(env.rootID, Constraints{MaxRows: rows, MaxCols: cols})
+        if err != nil {
+            return fmt.Errorf("Layout: %w", err)
+        }
+
+        err = env.ForwardResize(env.rootID, 0, 0, rows, cols)
+        if err != nil {
+            return fmt.Errorf("Layout: %w", err)
+        }
+    }
+
+    err := env.placeLayers(true)
     if err != nil {
-        return 0, fmt.Errorf("GetHeight: %w", err)
+        return fmt.Errorf("Layout: %w", err)
     }
 
-    return ee.e.GetHeight(ee.ectx), nil
+    return nil
+}
+
+// When an element's content changes in a way which changes its size hint,
+// it should request a layout. The layout will occur before the next draw.
+func (env *Environment) RequestLayout() {
+    env.layoutRequested = true
 }
 
-func (env *Environment) SetHeight(eid ElementID, h int) error {
+// ForwardResize places the given element at (r, c) with the given
+// number of rows and columns. The element's draw flag is set on success.
+func (env *Environment) ForwardResize(eid ElementID, r, c int, rows, cols int) error {
     ee, err := env.getEnvEntry(eid)
     if err != nil {
-        return fmt.Errorf("SetHeight: %w", err)
+        return fmt.Errorf("ForwardResize: %w", err)
     }
 
-    err = ee.e.SetHeight(ee.ectx, h)
+    err = ee.e.Resize(ee.ectx, r, c, rows, cols)
 
     if err != nil {
-        return fmt.Errorf("SetHeight: %w", err)
+        return fmt.Errorf("ForwardResize: %w", err)
     }
 
+    // The environment remembers where each element was placed for
+    // hit testing.
+    ee.ectx.r = r
+    ee.ectx.c = c
+    ee.ectx.rows = rows
+    ee.ectx.cols = cols
+
     ee.e.SetDrawFlag(true)
     return nil
 }
 
+// The given element (and its descendants) will be drawn through the given
+// viewport. (See Viewport)
+//
+// NOTE: Layout does not take viewports into account. An element with a
+// viewport should be resized within its own coordinate space.
 func (env *Environment) SetViewport(eid ElementID, vp Viewport) error {
     ee, err := env.getEnvEntry(eid)
     if err != nil {
@@ -394,10 +585,63 @@ func (env *Environment) SetViewport(eid ElementID, vp Viewport) error {
         return fmt.Errorf("SetViewport: %w", err)
     }
 
+    ee.ectx.viewport = &vp
+
+    // Everything inside the viewport has moved. Redrawing the element
+    // redraws all of its visible descendants. (See damage.go)
     ee.e.SetDrawFlag(true)
     return nil
 }
 
+// After this call, the given element will be drawn directly to the
+// screen again.
+func (env *Environment) ClearViewport(eid ElementID) error {
+    ee, err := env.getEnvEntry(eid)
+    if err != nil {
+        return fmt.Errorf("ClearViewport: %w", err)
+    }
+
+    ee.ectx.viewport = nil
+
+    ee.e.SetDrawFlag(true)
+    return nil
+}
+
+// A hidden element (and its descendants) stays registered and attached,
+// but is not drawn, can't be hit by the mouse and can't be focused.
+// If focus is within the element when it is hidden, focus is removed.
+// Events are not forwarded to hidden elements, so a hidden element and its
+// descendants receive no update ticks. (See ForwardEvent)
+func (env *Environment) SetHidden(eid ElementID, h bool) error {
+    ee, err := env.getEnvEntry(eid)
+    if err != nil {
+        return fmt.Errorf("SetHidden: %w", err)
+    }
+
+    if ee.ectx.hidden == h {
+        return nil
+    }
+
+    ee.ectx.hidden = h
+
+    if !h {
+        ee.e.SetDrawFlag(true)
+        return nil
+    }
+
+    // Whatever the element covered must be drawn again.
+    env.damageTree(eid)
+
+    if env.focusWithin(eid) {
+        err = env.Blur()
+        if err != nil {
+            return fmt.Errorf("SetHidden: %w", err)
+        }
+    }
+
+    return nil
+}
+
 // Event Forwarding Functions.
 
 func (env *Environment) ForwardEvent(eid ElementID, ev tcell.Event) error {
@@ -406,7 +650,21 @@ func (env *Environment) ForwardEvent(eid ElementID, ev tcell.Event) error {
         return fmt.Errorf("ForwardEvent: %w", err)
     }
 
+    // Skipping a hidden element also skips its descendants.
+    if ee.ectx.hidden {
+        return nil
+    }
+
+    // Forwarded events do not propagate.
+    env.pushDispatch(&dispatchState{
+        target: eid,
+        phase: PHASE_NONE,
+        stopped: false,
+        handled: false,
+    })
     err = ee.e.HandleEvent(ee.ectx, ev)
+    env.popDispatch()
+
     if err != nil {
         return fmt.Errorf("ForwardEvent: %w", err)
     }
@@ -424,35 +682,87 @@ func (env *Environment) SetDrawFlag(eid ElementID) error {
     return nil
 }
 
-// This returns true if and only if Draw was called on at least one element.
-// This begins drawing starting at the root. Then going down.
-func (env *Environment) Draw() bool {
+// Sets the draw flag of the given element and all of its descendants.
+func (env *Environment) SetDrawFlagTree(eid ElementID) error {
+    _, err := env.getEnvEntry(eid)
+    if err != nil {
+        return fmt.Errorf("SetDrawFlagTree: %w", err)
+    }
+
+    env.setDrawFlagTree(eid)
+    return nil
+}
+
+func (env *Environment) setDrawFlagTree(eid ElementID) {
+    ee := env.entry(eid)
+    ee.e.SetDrawFlag(true)
+
+    for _, cctx := range ee.ectx.children {
+        env.setDrawFlagTree(cctx.id)
+    }
+}
+
+// Draw redraws every element which overlaps a damaged region of the screen.
+// (See damage.go)
+// The damaged regions are returned, if nothing was drawn, this is empty.
+func (env *Environment) Draw() []Rect {
+    cols, rows := env.screen.Size()
+    roots := env.treeRoots()
+
+    for _, eid := range roots {
+        env.collectDamage(eid, frame{clip: NewRect(0, 0, rows, cols)})
+    }
+
+    if len(env.damage) == 0 {
+        return nil
+    }
+
+    ds := &damageScreen{Screen: env.screen, env: env}
+
     if env.rootID != NULL_EID {
-        return env.draw(env.rootID)
+        env.draw(env.rootID, ds)
+    }
+
+    for _, l := range env.layers {
+        if l.spec.backdrop {
+            env.dimBackdrop()
+        }
+
+        env.draw(l.rootID, ds)
     }
 
-    return false
+    regions := make([]Rect, len(env.damage))
+    copy(regions, env.damage)
+    env.damage = env.damage[:0]
+
+    return regions
 }
 
 // Draw recursive helper.
-func (env *Environment) draw(eid ElementID) bool {
-    ee := env.elements[eid]
+// s is the screen the given element should draw to.
+func (env *Environment) draw(eid ElementID, s tcell.Screen) {
+    ee := env.entry(eid)
 
-    drawOccured := false    
+    if ee.ectx.hidden {
+        return
+    }
+
+    // An element with a viewport (and its descendants) draws through it.
+    if ee.ectx.viewport != nil {
+        s = newViewportScreen(s, *(ee.ectx.viewport))
+    }
 
     // Draw parent first.
-    if ee.e.GetDrawFlag() {
-        ee.e.Draw(env.screen)
-        ee.e.SetDrawFlag(false)
-        drawOccured = true
+    if env.damaged(ee.ectx.drawnRect) {
+        ectx := ee.ectx
+        ee.e.Draw(NewCanvas(s, ectx.r, ectx.c, ectx.rows, ectx.cols))
     }
+    ee.e.SetDrawFlag(false)
 
     // Next draw children.
     for _, cctx := range ee.ectx.children {
-        drawOccured = env.draw(cctx.id) || drawOccured
+        env.draw(cctx.id, s)
     }
-
-    return drawOccured
 }
 
 // NOTE: Unlike register, this Deregister is recursive.
@@ -462,6 +772,10 @@ func (env *Environment) Deregister(eid ElementID) error {
         return fmt.Errorf("Deregister: Cannot deregister root: %d", eid)
     }
 
+    if env.layerIndex(eid) != -1 {
+        return fmt.Errorf("Deregister: Cannot deregister layer: %d", eid)
+    }
+
     ee, err := env.getEnvEntry(eid)
     if err != nil {
         return fmt.Errorf("Deregister: %w", err)
@@ -478,39 +792,71 @@ func (env *Environment) Deregister(eid ElementID) error {
         cid := cctx.id
 
         // sever parent tie. 
-        env.elements[cid].ectx.parentID = NULL_EID
+        env.entry(cid).ectx.parentID = NULL_EID
         env.Deregister(cid) // this should always succeed.
     }
 
+    // An element is always blurred before it is stopped.
+    if env.focusID == eid {
+        env.Blur()
+    }
+
+    if env.hoverID == eid {
+        env.hoverID = NULL_EID
+    }
+
+    if env.captureID == eid {
+        env.captureID = NULL_EID
+    }
+
+    env.HideCursor(eid)
+
+    env.cancelTimers(ectx)
+
     ee.e.Stop()
 
     // finally, remove this guy from the env
-    env.elements[eid] = nil
+    // His ID is now stale.
+    env.elements[eid.slot()] = nil
+    env.generations[eid.slot()]++
 
     env.fill--
-
-    // If our map was previously full, let's set our
-    // pointer id to eid to increase speed of next register.
-    if env.fill == len(env.elements) - 1 {
-        env.ptrID = eid
-    }
+    env.freeSlots = append(env.freeSlots, eid.slot())
 
     return nil
 }
 
+// The most elements which can be registered at once.
+// This can be UNLIMITED_CAPACITY.
+func (env *Environment) MaxCapacity() int {
+    return env.maxCapacity
+}
+
+// The number of elements currently registered.
+func (env *Environment) Fill() int {
+    return env.fill
+}
+
+// The number of slots in the elements map, used or not.
+// This never shrinks.
+func (env *Environment) Slots() int {
+    return len(env.elements)
+}
+
 // This deregisters all elements in the Environment!
 // Essenstially a clean up call.
 func (env *Environment) DeregisterAll() error {
-    // Make sure to clear the root.
+    // Make sure to clear the root and layers.
     env.rootID = NULL_EID
+    env.layers = env.layers[:0]
 
     for i := range env.elements {
         ee := env.elements[i]
 
         // Only deregister elements with no parents.
         // Others will be dealt with recursively.
-        if ee.ectx.parentID == NULL_EID {
-            err := env.Deregister(ElementID(i))
+        if ee != nil && ee.ectx.parentID == NULL_EID {
+            err := env.Deregister(ee.ectx.selfID)
 
             // A single error stops the whole thing.
             if err != nil {
@@ -542,17 +888,111 @@ func (u UpdateTickEvent) When() time.Time {
 //    including sleep time if needed.
 //
 // 2) Synchronosly process all queued tcell events through
-//    through the root.
+//    through the root. (Key events go to the focused element,
+//    mouse events go to the element under the cursor)
+//
+// 3) Perform all work posted from other goroutines.
 //
-// 3) Calculate how many ticks occured during the elapsed time of the 
+// 4) Calculate how many ticks occured during the elapsed time of the 
 //    last iteration. Send that many update events through the root.
 //
-// 4) Draw!
+// 5) Draw!
+
+// ProcessEvent handles a single tcell event exactly like Run would.
+// (This is exported so the loop can be driven by hand, e.g. in tests)
+func (env *Environment) ProcessEvent(e tcell.Event) error {
+    var err error
+
+    switch ev := e.(type) {
+    case *tcell.EventResize:
+        env.Invalidate()
+        err = env.Layout()
+
+    case *tcell.EventKey:
+        if ev.Key() == tcell.KeyCtrlC {
+            env.exitRequested = true
+            break
+        }
+
+        err = env.forwardKey(ev)
+
+    case *tcell.EventPaste:
+        err = env.forwardPaste(ev)
+
+    case *tcell.EventMouse:
+        err = env.routeMouse(ev)
+
+    default:
+        err = env.broadcast(e)
+    }
+
+    if err != nil {
+        return fmt.Errorf("ProcessEvent: %w", err)
+    }
+
+    return nil
+}
+
+// Tick sends a single update tick through the root and every layer.
+// Then, the environment's clock is advanced and due timers are fired.
+func (env *Environment) Tick() error {
+    err := env.broadcast(NewUpdateTickEvent())
+    if err != nil {
+        return fmt.Errorf("Tick: %w", err)
+    }
+
+    env.clock += env.updateDur
+
+    err = env.fireTimers()
+    if err != nil {
+        return fmt.Errorf("Tick: %w", err)
+    }
+
+    return nil
+}
+
+// Render performs a layout (if one was requested), then draws and shows
+// whatever needs to be redrawn.
+func (env *Environment) Render() error {
+    if env.layoutRequested {
+        err := env.Layout()
+        if err != nil {
+            return fmt.Errorf("Render: %w", err)
+        }
+    } else {
+        // Layers anchored to elements follow them.
+        err := env.placeLayers(false)
+        if err != nil {
+            return fmt.Errorf("Render: %w", err)
+        }
+    }
+
+    drawn := len(env.Draw()) > 0
+    moved := env.placeCursor()
+
+    if drawn || moved {
+        env.screen.Show()
+    }
+
+    return nil
+}
+
+func (env *Environment) ExitRequested() bool {
+    return env.exitRequested
+}
 
 func (env *Environment) Run() error {
     // Clear our screen before doing anything else.
     env.screen.Clear()
     env.screen.Show()
+    env.Invalidate()
+
+    env.screen.EnableMouse()
+    defer env.screen.DisableMouse()
+
+    // Pasted text arrives between a pair of paste events.
+    env.screen.EnablePaste()
+    defer env.screen.DisablePaste()
 
     env.exitRequested = false
     var err error
@@ -579,26 +1019,7 @@ func (env *Environment) Run() error {
 
         // First poll for system events.
         for env.screen.HasPendingEvent() {
-            e := env.screen.PollEvent()
-
-            switch ev := e.(type) {
-            case *tcell.EventResize:
-                cols, rows := ev.Size()
-                err = env.ForwardResize(env.rootID, 0, 0, rows, cols)
-                break
-
-            case *tcell.EventKey:
-                if ev.Key() == tcell.KeyCtrlC {
-                    env.exitRequested = true
-                    break
-                }
-
-                err = env.ForwardEvent(env.rootID, ev)
-                break
-            default:
-                err = env.ForwardEvent(env.rootID, e)
-                break
-            }
+            err = env.ProcessEvent(env.screen.PollEvent())
 
             if env.exitRequested {
                 return nil
@@ -609,19 +1030,29 @@ func (env *Environment) Run() error {
             }
         }
 
+        // Next, perform posted work.
+        err = env.ProcessPosted()
+        if err != nil {
+            return fmt.Errorf("Run: %w", err)
+        }
+
+        if env.exitRequested {
+            return nil
+        }
+
         // Now let's send our update ticks.
         ticksPassed := int(lastIterDur / expIterDur)
         for i := 0; i < ticksPassed; i++ {
-            err = env.ForwardEvent(env.rootID, NewUpdateTickEvent())
+            err = env.Tick()
             if err != nil {
                 return fmt.Errorf("Run: %w", err)
             }
         }
 
         // Finally, time to draw!
-        if env.Draw() {
-            env.screen.Show()
+        err = env.Render()
+        if err != nil {
+            return fmt.Errorf("Run: %w", err)
         }
     }
 }
-
@@ -0,0 +1,197 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Returns an environment with the given max capacity and nothing registered.
+func newTestEnv(t *testing.T, mc int) *Environment {
+    s := tcell.NewSimulationScreen("UTF-8")
+    err := s.Init()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    t.Cleanup(s.Fini)
+
+    return NewEnvironment(s, mc, 10 * time.Millisecond)
+}
+
+func mustRegister(t *testing.T, env *Environment) ElementID {
+    t.Helper()
+
+    eid, err := env.Register(NewDefaultElement())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return eid
+}
+
+func TestElementIDLookup(t *testing.T) {
+    env := newTestEnv(t, UNLIMITED_CAPACITY)
+
+    old := mustRegister(t, env)
+    if err := env.Deregister(old); err != nil {
+        t.Fatal(err)
+    }
+
+    // The freed slot is reused under a new generation.
+    curr := mustRegister(t, env)
+    if curr.slot() != old.slot() || curr == old {
+        t.Fatalf("expected slot %d reused with a new generation, got %d", old.slot(), curr)
+    }
+
+    cases := []struct {
+        name string
+        eid ElementID
+        expected error
+    }{
+        {"current", curr, nil},
+        {"stale", old, ErrStaleElementID},
+        {"future generation", makeElementID(curr.slot(), curr.generation() + 1), ErrUnknownElementID},
+        {"unused slot", makeElementID(5, 0), ErrUnknownElementID},
+        {"null", NULL_EID, ErrUnknownElementID},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            _, err := env.GetElementContext(tc.eid)
+
+            if tc.expected == nil && err != nil {
+                t.Errorf("expected no error, got %v", err)
+            }
+
+            if tc.expected != nil && !errors.Is(err, tc.expected) {
+                t.Errorf("expected %v, got %v", tc.expected, err)
+            }
+        })
+    }
+}
+
+// Stale IDs are rejected everywhere, not just by lookups.
+func TestStaleElementIDRejected(t *testing.T) {
+    env := newTestEnv(t, UNLIMITED_CAPACITY)
+
+    old := mustRegister(t, env)
+    if err := env.Deregister(old); err != nil {
+        t.Fatal(err)
+    }
+    mustRegister(t, env)
+
+    calls := []struct {
+        name string
+        call func () error
+    }{
+        {"Deregister", func () error { return env.Deregister(old) }},
+        {"MakeRoot", func () error { return env.MakeRoot(old) }},
+        {"SetHidden", func () error { return env.SetHidden(old, true) }},
+        {"ForwardEvent", func () error { return env.ForwardEvent(old, NewUpdateTickEvent()) }},
+        {"Dispatch", func () error {
+            _, err := env.Dispatch(old, NewUpdateTickEvent())
+            return err
+        }},
+    }
+
+    for _, c := range calls {
+        if err := c.call(); !errors.Is(err, ErrStaleElementID) {
+            t.Errorf("%s: expected %v, got %v", c.name, ErrStaleElementID, err)
+        }
+    }
+
+    if env.Fill() != 1 {
+        t.Errorf("expected 1 element registered, got %d", env.Fill())
+    }
+}
+
+func TestFreeSlots(t *testing.T) {
+    env := newTestEnv(t, UNLIMITED_CAPACITY)
+
+    ids := make([]ElementID, 4)
+    for i := range ids {
+        ids[i] = mustRegister(t, env)
+    }
+
+    // A parent with two children frees three slots at once.
+    for _, cid := range ids[2:] {
+        _, err := env.Attach(ids[1], cid)
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    err := env.Deregister(ids[0])
+    if err == nil {
+        err = env.Deregister(ids[1])
+    }
+
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if env.Fill() != 0 || env.Slots() != 4 {
+        t.Fatalf("expected 0 elements in 4 slots, got %d in %d", env.Fill(), env.Slots())
+    }
+
+    // The most recently freed slot is reused first. Slots are only added
+    // once every slot is in use.
+    expected := []int{1, 3, 2, 0, 4}
+    for _, slot := range expected {
+        if eid := mustRegister(t, env); eid.slot() != slot {
+            t.Errorf("expected slot %d, got %d", slot, eid.slot())
+        }
+    }
+
+    if env.Slots() != 5 {
+        t.Errorf("expected 5 slots, got %d", env.Slots())
+    }
+}
+
+func TestCapacity(t *testing.T) {
+    cases := []struct {
+        name string
+        capacity int
+        registers int
+        fits int
+    }{
+        {"under", 3, 2, 2},
+        {"at", 3, 3, 3},
+        {"over", 3, 5, 3},
+        {"empty", 0, 1, 0},
+        {"unlimited", UNLIMITED_CAPACITY, 100, 100},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            env := newTestEnv(t, tc.capacity)
+
+            ids := make([]ElementID, 0)
+            for i := 0; i < tc.registers; i++ {
+                eid, err := env.Register(NewDefaultElement())
+                if err == nil {
+                    ids = append(ids, eid)
+                }
+            }
+
+            if len(ids) != tc.fits || env.Fill() != tc.fits {
+                t.Fatalf("expected %d elements registered, got %d", tc.fits, env.Fill())
+            }
+
+            if len(ids) == 0 {
+                return
+            }
+
+            // Freeing an element makes room again.
+            err := env.Deregister(ids[0])
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            mustRegister(t, env)
+        })
+    }
+}
@@ -0,0 +1,286 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Elements which can hold keyboard focus implement this interface.
+// When an element has focus, key events are dispatched to it.
+//
+// AcceptsFocus is checked every time focus moves, so an element can
+// refuse focus temporarily. (e.g. When it is disabled)
+type Focusable interface {
+    AcceptsFocus() bool
+}
+
+// Sent to an element when it gains focus.
+type FocusEvent struct {
+    at time.Time
+}
+
+func NewFocusEvent() *FocusEvent {
+    return &FocusEvent{
+        at: time.Now(),
+    }
+}
+
+func (fe FocusEvent) When() time.Time {
+    return fe.at
+}
+
+// Sent to an element when it loses focus.
+type BlurEvent struct {
+    at time.Time
+}
+
+func NewBlurEvent() *BlurEvent {
+    return &BlurEvent{
+        at: time.Now(),
+    }
+}
+
+func (be BlurEvent) When() time.Time {
+    return be.at
+}
+
+func (env *Environment) FocusedID() ElementID {
+    return env.focusID
+}
+
+// Returns true if the given element can currently receive focus.
+func (env *Environment) acceptsFocus(eid ElementID) bool {
+    f, ok := env.entry(eid).e.(Focusable)
+    return ok && f.AcceptsFocus()
+}
+
+// Returns true if the given element is part of a tree which can receive
+// input. (See activeRoots) Hidden elements are not part of any tree.
+func (env *Environment) inTree(eid ElementID) bool {
+    top := eid
+    for ; eid != NULL_EID; eid = env.entry(eid).ectx.parentID {
+        if env.entry(eid).ectx.hidden {
+            return false
+        }
+
+        top = eid
+    }
+
+    for _, rid := range env.activeRoots() {
+        if top == rid {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Returns all elements which accept focus in tree order.
+// (Parents come before their children, children are in index order)
+func (env *Environment) focusOrder() []ElementID {
+    order := make([]ElementID, 0)
+
+    for _, rid := range env.activeRoots() {
+        order = env.appendFocusOrder(order, rid)
+    }
+
+    return order
+}
+
+func (env *Environment) appendFocusOrder(order []ElementID, eid ElementID) []ElementID {
+    if env.entry(eid).ectx.hidden {
+        return order
+    }
+
+    if env.acceptsFocus(eid) {
+        order = append(order, eid)
+    }
+
+    for _, cctx := range env.entry(eid).ectx.children {
+        order = env.appendFocusOrder(order, cctx.id)
+    }
+
+    return order
+}
+
+// Returns true if the focused element is the given element or one of
+// its descendants.
+func (env *Environment) focusWithin(eid ElementID) bool {
+    for fid := env.focusID; fid != NULL_EID; fid = env.entry(fid).ectx.parentID {
+        if fid == eid {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Gives focus to the given element. The previously focused element is
+// sent a BlurEvent, the newly focused element is sent a FocusEvent.
+//
+// Only elements which accept focus and are part of the root's tree (or an
+// active layer's tree) can be focused. (See layer.go)
+func (env *Environment) Focus(eid ElementID) error {
+    ee, err := env.getEnvEntry(eid)
+    if err != nil {
+        return fmt.Errorf("Focus: %w", err)
+    }
+
+    if env.focusID == eid {
+        return nil
+    }
+
+    f, ok := ee.e.(Focusable)
+    if !ok || !f.AcceptsFocus() {
+        return fmt.Errorf("Focus: Element does not accept focus: %d", eid)
+    }
+
+    if !env.inTree(eid) {
+        return fmt.Errorf("Focus: Element is not in an active tree: %d", eid)
+    }
+
+    err = env.Blur()
+    if err != nil {
+        return fmt.Errorf("Focus: %w", err)
+    }
+
+    env.focusID = eid
+
+    _, err = env.dispatch(eid, func (ElementID) tcell.Event {
+        return NewFocusEvent()
+    }, false)
+    if err != nil {
+        return fmt.Errorf("Focus: %w", err)
+    }
+
+    return nil
+}
+
+// Removes focus from the focused element (if there is one).
+func (env *Environment) Blur() error {
+    if env.focusID == NULL_EID {
+        return nil
+    }
+
+    eid := env.focusID
+    env.focusID = NULL_EID
+
+    _, err := env.dispatch(eid, func (ElementID) tcell.Event {
+        return NewBlurEvent()
+    }, false)
+    if err != nil {
+        return fmt.Errorf("Blur: %w", err)
+    }
+
+    return nil
+}
+
+// Moves focus forward (or backward) through the focus order, wrapping around
+// at the ends. If nothing is focused, the first (or last) element
+// in the focus order is focused.
+func (env *Environment) moveFocus(forward bool) error {
+    order := env.focusOrder()
+    if len(order) == 0 {
+        return env.Blur()
+    }
+
+    curr := -1
+    for i, eid := range order {
+        if eid == env.focusID {
+            curr = i
+            break
+        }
+    }
+
+    var next int
+    if forward {
+        next = (curr + 1) % len(order)
+    } else if curr == -1 {
+        next = len(order) - 1
+    } else {
+        next = (curr - 1 + len(order)) % len(order)
+    }
+
+    return env.Focus(order[next])
+}
+
+func (env *Environment) FocusNext() error {
+    err := env.moveFocus(true)
+    if err != nil {
+        return fmt.Errorf("FocusNext: %w", err)
+    }
+
+    return nil
+}
+
+func (env *Environment) FocusPrev() error {
+    err := env.moveFocus(false)
+    if err != nil {
+        return fmt.Errorf("FocusPrev: %w", err)
+    }
+
+    return nil
+}
+
+// Returns the element keyboard input is dispatched to.
+// This is the focused element. If no element is focused, it is the root,
+// or the topmost modal layer if there is one.
+func (env *Environment) keyTarget() (ElementID, error) {
+    // The focused element may have been detached from the tree.
+    if env.focusID != NULL_EID && !env.inTree(env.focusID) {
+        err := env.Blur()
+        if err != nil {
+            return NULL_EID, err
+        }
+    }
+
+    target := env.focusID
+    if target == NULL_EID {
+        target = env.rootID
+
+        if m := env.modalIndex(); m != -1 {
+            target = env.layers[m].rootID
+        }
+    }
+
+    return target, nil
+}
+
+// Paste events are dispatched like key events. (See keyTarget)
+// The pasted text arrives as key events between a paste start and
+// paste end event.
+func (env *Environment) forwardPaste(ev *tcell.EventPaste) error {
+    target, err := env.keyTarget()
+    if err != nil || target == NULL_EID {
+        return err
+    }
+
+    _, err = env.Dispatch(target, ev)
+    return err
+}
+
+// Key events are dispatched to the key target. (See keyTarget)
+//
+// NOTE: If no handler marks a Tab or Shift-Tab as handled, focus moves.
+func (env *Environment) forwardKey(ev *tcell.EventKey) error {
+    target, err := env.keyTarget()
+    if err != nil || target == NULL_EID {
+        return err
+    }
+
+    handled, err := env.Dispatch(target, ev)
+    if err != nil || handled {
+        return err
+    }
+
+    switch ev.Key() {
+    case tcell.KeyTab:
+        return env.FocusNext()
+    case tcell.KeyBacktab:
+        return env.FocusPrev()
+    }
+
+    return nil
+}
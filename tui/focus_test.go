@@ -0,0 +1,241 @@
+package tui_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+// A probe records the events it handles in a log shared with other
+// probes. Entries look like "name:event".
+type probe struct {
+    *tui.DefaultElement
+
+    id tui.ElementID
+    name string
+    log *[]string
+
+    focusable bool
+
+    // Called after each event is logged, when set.
+    onEvent func (*tui.ElementContext, tcell.Event) error
+}
+
+func newProbe(name string, log *[]string, focusable bool) *probe {
+    return &probe{
+        DefaultElement: tui.NewDefaultElement(),
+        id: tui.NULL_EID,
+        name: name,
+        log: log,
+        focusable: focusable,
+        onEvent: nil,
+    }
+}
+
+func (p *probe) F() tui.ElementFactory {
+    return func (env *tui.Environment) (tui.ElementID, error) {
+        eid, err := env.Register(p)
+        p.id = eid
+
+        return eid, err
+    }
+}
+
+func (p *probe) AcceptsFocus() bool {
+    return p.focusable
+}
+
+var mouseActionNames = map[tui.MouseAction]string{
+    tui.MOUSE_PRESS: "press",
+    tui.MOUSE_RELEASE: "release",
+    tui.MOUSE_CLICK: "click",
+    tui.MOUSE_MOVE: "move",
+    tui.MOUSE_DRAG: "drag",
+    tui.MOUSE_WHEEL: "wheel",
+    tui.MOUSE_ENTER: "enter",
+    tui.MOUSE_LEAVE: "leave",
+}
+
+func (p *probe) HandleEvent(ectx *tui.ElementContext, ev tcell.Event) error {
+    entry := ""
+
+    switch tev := ev.(type) {
+    case *tui.FocusEvent:
+        entry = "focus"
+    case *tui.BlurEvent:
+        entry = "blur"
+    case *tui.MouseEvent:
+        entry = mouseActionNames[tev.Action()]
+    case *tcell.EventKey:
+        entry = "key"
+    }
+
+    if entry != "" {
+        *p.log = append(*p.log, p.name + ":" + entry)
+    }
+
+    if p.onEvent != nil {
+        return p.onEvent(ectx, ev)
+    }
+
+    return nil
+}
+
+func expectLog(t *testing.T, log []string, expected ...string) {
+    t.Helper()
+
+    if !slices.Equal(log, expected) {
+        t.Errorf("expected %q, got %q", expected, log)
+    }
+}
+
+// Probes a to d side by side. b does not accept focus, c sits inside a
+// nested divided element.
+type focusFixture struct {
+    h *tuitest.Harness
+    log []string
+    a, b, c, d *probe
+}
+
+func newFocusFixture(t *testing.T) *focusFixture {
+    ff := &focusFixture{}
+    ff.a = newProbe("a", &ff.log, true)
+    ff.b = newProbe("b", &ff.log, false)
+    ff.c = newProbe("c", &ff.log, true)
+    ff.d = newProbe("d", &ff.log, true)
+
+    ff.h = tuitest.New(t, 1, 20, tui.DividedElementF(true, false, plain,
+        tui.FlexDivision(1, ff.a.F()),
+        tui.FlexDivision(1, ff.b.F()),
+        tui.FlexDivision(1, tui.DividedElementF(false, false, plain,
+            tui.FlexDivision(1, ff.c.F()))),
+        tui.FlexDivision(1, ff.d.F())))
+
+    return ff
+}
+
+// The name of the focused probe, or "" if no probe is focused.
+func (ff *focusFixture) focused() string {
+    fid := ff.h.Env().FocusedID()
+    for _, p := range []*probe{ff.a, ff.b, ff.c, ff.d} {
+        if p.id == fid {
+            return p.name
+        }
+    }
+
+    return ""
+}
+
+func TestFocusTraversal(t *testing.T) {
+    tab := tcell.KeyTab
+    backtab := tcell.KeyBacktab
+
+    cases := []struct {
+        name string
+        keys []tcell.Key
+        expected string
+    }{
+        {"tab from nothing", []tcell.Key{tab}, "a"},
+        {"tab skips unfocusable", []tcell.Key{tab, tab}, "c"},
+        {"tab out of nested", []tcell.Key{tab, tab, tab}, "d"},
+        {"tab wraps", []tcell.Key{tab, tab, tab, tab}, "a"},
+        {"backtab from nothing", []tcell.Key{backtab}, "d"},
+        {"backtab wraps", []tcell.Key{tab, backtab}, "d"},
+        {"backtab skips unfocusable", []tcell.Key{backtab, backtab, backtab}, "a"},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            ff := newFocusFixture(t)
+
+            for _, k := range tc.keys {
+                ff.h.Key(k, tcell.ModNone)
+            }
+
+            if actual := ff.focused(); actual != tc.expected {
+                t.Errorf("expected %q focused, got %q", tc.expected, actual)
+            }
+        })
+    }
+}
+
+func TestFocusEvents(t *testing.T) {
+    ff := newFocusFixture(t)
+
+    ff.h.Key(tcell.KeyTab, tcell.ModNone)
+    ff.h.Key(tcell.KeyTab, tcell.ModNone)
+
+    // Tab is sent to the focused element before focus moves.
+    expectLog(t, ff.log, "a:focus", "a:key", "a:blur", "c:focus")
+}
+
+// Elements which stop accepting focus are skipped.
+func TestFocusRefused(t *testing.T) {
+    ff := newFocusFixture(t)
+    ff.c.focusable = false
+
+    ff.h.Key(tcell.KeyTab, tcell.ModNone)
+    ff.h.Key(tcell.KeyTab, tcell.ModNone)
+
+    if ff.focused() != "d" {
+        t.Errorf("expected %q focused, got %q", "d", ff.focused())
+    }
+
+    err := ff.h.Env().Focus(ff.c.id)
+    if err == nil {
+        t.Error("expected an error focusing an element which refuses focus")
+    }
+}
+
+func TestFocusRemoved(t *testing.T) {
+    cases := []struct {
+        name string
+        remove func (env *tui.Environment, eid tui.ElementID) error
+    }{
+        {"hidden", func (env *tui.Environment, eid tui.ElementID) error {
+            return env.SetHidden(eid, true)
+        }},
+        {"deregistered", func (env *tui.Environment, eid tui.ElementID) error {
+            ectx, err := env.GetElementContext(eid)
+            if err != nil {
+                return err
+            }
+
+            return ectx.DetachAndDeregister()
+        }},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            ff := newFocusFixture(t)
+            env := ff.h.Env()
+
+            err := env.Focus(ff.c.id)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            err = tc.remove(env, ff.c.id)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            if env.FocusedID() != tui.NULL_EID {
+                t.Errorf("expected nothing focused, got %q", ff.focused())
+            }
+
+            expectLog(t, ff.log, "c:focus", "c:blur")
+
+            // c is no longer part of the focus order.
+            ff.h.Key(tcell.KeyTab, tcell.ModNone)
+            ff.h.Key(tcell.KeyTab, tcell.ModNone)
+
+            if ff.focused() != "d" {
+                t.Errorf("expected %q focused, got %q", "d", ff.focused())
+            }
+        })
+    }
+}
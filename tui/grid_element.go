@@ -0,0 +1,293 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Grid Element --------------------------------------
+
+// A grid element places its children into cells.
+// The grid's rows and columns (tracks) are each sized using a DivisionSpec.
+// (See division.go)
+//
+// A child can span multiple rows and/or columns.
+// Children may overlap, in which case later children are drawn on top.
+
+type GridElement struct {
+    *DefaultElement
+
+    rowSpecs []DivisionSpec
+    colSpecs []DivisionSpec
+
+    // Space not covered by any child takes this style.
+    style tcell.Style
+}
+
+// The child attribute key which all children of a grid element
+// must have. It maps to a GridCell.
+const GRID_CELL_ATTR = "grid-cell"
+
+// Where a child is placed inside a grid element.
+type GridCell struct {
+    row, col int
+    rowSpan, colSpan int
+
+    // Set by cells when the spans had to be clipped to fit the grid.
+    clipped bool
+}
+
+func NewGridCell(row, col int, rowSpan, colSpan int) GridCell {
+    return GridCell{
+        row: row,
+        col: col,
+        rowSpan: rowSpan,
+        colSpan: colSpan,
+        clipped: false,
+    }
+}
+
+func NewGridElement(rowSpecs []DivisionSpec, colSpecs []DivisionSpec, s tcell.Style) *GridElement {
+    return &GridElement{
+        DefaultElement: NewDefaultElement(),
+        rowSpecs: rowSpecs,
+        colSpecs: colSpecs,
+        style: s,
+    }
+}
+
+// A grid item pairs an element factory with the cell its
+// element should occupy inside a grid element.
+type GridItem struct {
+    cell GridCell
+    ef ElementFactory
+}
+
+func GridItemAt(row, col int, ef ElementFactory) GridItem {
+    return GridItem{
+        cell: NewGridCell(row, col, 1, 1),
+        ef: ef,
+    }
+}
+
+func GridSpanAt(row, col int, rowSpan, colSpan int, ef ElementFactory) GridItem {
+    return GridItem{
+        cell: NewGridCell(row, col, rowSpan, colSpan),
+        ef: ef,
+    }
+}
+
+func GridElementF(rowSpecs []DivisionSpec, colSpecs []DivisionSpec, s tcell.Style, items ...GridItem) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        eid, err := env.Register(NewGridElement(rowSpecs, colSpecs, s))
+        if err != nil {
+            return -1, err
+        }
+
+        for _, item := range items {
+            cid, err := item.ef(env)
+            if err != nil {
+                // Earlier children are deregistered along with the
+                // parent. This will never error.
+                env.Deregister(eid)
+                return -1, err
+            }
+
+            // These will never error.
+            index, _ := env.Attach(eid, cid)
+            env.SetChildAttr(eid, index, GRID_CELL_ATTR, item.cell)
+        }
+
+        return eid, nil
+    }
+}
+
+// Creates, registers and attaches a new grid item at the end of the given
+// grid element's context.
+//
+// NOTE: The grid element will need to be resized for the new item to be
+// given space.
+func AttachGridItem(ectx *ElementContext, item GridItem) (int, error) {
+    index, err := ectx.CreateRegisterAndAttach(item.ef)
+    if err != nil {
+        return -1, fmt.Errorf("AttachGridItem: %w", err)
+    }
+
+    // This will never error.
+    ectx.SetChildAttr(index, GRID_CELL_ATTR, item.cell)
+
+    return index, nil
+}
+
+// Returns the cell of every child.
+// Spans are clipped to the grid, a cell entirely outside the grid will have
+// zero spans. Clipped cells are marked as such.
+func (ge *GridElement) cells(ectx *ElementContext) ([]GridCell, error) {
+    numChildren := ectx.NumChildren()
+    cells := make([]GridCell, numChildren)
+
+    for i := 0; i < numChildren; i++ {
+        val, err := ectx.GetChildAttr(i, GRID_CELL_ATTR)
+        if err != nil {
+            return nil, fmt.Errorf("cells: %w", err)
+        }
+
+        cell, ok := val.(GridCell)
+        if !ok {
+            return nil, fmt.Errorf("cells: grid-cell has incorrect type: %d", i)
+        }
+
+        if cell.row < 0 || cell.col < 0 || cell.rowSpan < 1 || cell.colSpan < 1 {
+            return nil, fmt.Errorf("cells: Bad grid cell: %d", i)
+        }
+
+        rowSpan := max(min(cell.rowSpan, len(ge.rowSpecs) - cell.row), 0)
+        colSpan := max(min(cell.colSpan, len(ge.colSpecs) - cell.col), 0)
+
+        cell.clipped = rowSpan != cell.rowSpan || colSpan != cell.colSpan
+        cell.rowSpan = rowSpan
+        cell.colSpan = colSpan
+
+        cells[i] = cell
+    }
+
+    return cells, nil
+}
+
+// Calculates the preferred size of each row and column track.
+// Only children which span a single track contribute to that
+// track's preferred size. Clipped children never contribute, since part
+// of them lies outside of the grid.
+func (ge *GridElement) trackPrefs(ectx *ElementContext, cells []GridCell,
+    cons Constraints) ([]int, []int) {
+    rowPrefs := make([]int, len(ge.rowSpecs))
+    colPrefs := make([]int, len(ge.colSpecs))
+
+    for i, cell := range cells {
+        if cell.clipped || (cell.rowSpan != 1 && cell.colSpan != 1) {
+            continue
+        }
+
+        cctx, _ := ectx.Child(i)
+        hint := cctx.Measure(cons)
+
+        if cell.rowSpan == 1 {
+            rowPrefs[cell.row] = max(rowPrefs[cell.row], hint.PrefRows)
+        }
+
+        if cell.colSpan == 1 {
+            colPrefs[cell.col] = max(colPrefs[cell.col], hint.PrefCols)
+        }
+    }
+
+    return rowPrefs, colPrefs
+}
+
+// A grid element would like enough room for the basis of every track.
+func (ge *GridElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    hint := ge.DefaultElement.Measure(ectx, cons)
+
+    cells, err := ge.cells(ectx)
+    if err != nil {
+        // Resize will report the error.
+        return hint
+    }
+
+    rowPrefs, colPrefs := ge.trackPrefs(ectx, cells, cons)
+
+    for i, spec := range ge.rowSpecs {
+        hint.PrefRows += spec.Resolve(cons.MaxRows, rowPrefs[i]).basis
+    }
+
+    for i, spec := range ge.colSpecs {
+        hint.PrefCols += spec.Resolve(cons.MaxCols, colPrefs[i]).basis
+    }
+
+    return hint
+}
+
+// Converts track dimmensions into track offsets.
+// The returned slice has one more entry than dims, the final entry
+// is the total size of all tracks.
+func trackOffsets(dims []int) []int {
+    offsets := make([]int, len(dims) + 1)
+    for i, dim := range dims {
+        offsets[i+1] = offsets[i] + dim
+    }
+
+    return offsets
+}
+
+func (ge *GridElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := ge.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    cells, err := ge.cells(ectx)
+    if err != nil {
+        return fmt.Errorf("Resize: %w", err)
+    }
+
+    rowPrefs, colPrefs := ge.trackPrefs(ectx, cells,
+        Constraints{MaxRows: rows, MaxCols: cols})
+
+    rowDims, err := mapToDims(rows, ge.rowSpecs, rowPrefs)
+    if err != nil {
+        return fmt.Errorf("Resize: %w", err)
+    }
+
+    colDims, err := mapToDims(cols, ge.colSpecs, colPrefs)
+    if err != nil {
+        return fmt.Errorf("Resize: %w", err)
+    }
+
+    rowOffsets := trackOffsets(rowDims)
+    colOffsets := trackOffsets(colDims)
+
+    for i, cell := range cells {
+        cctx, _ := ectx.Child(i)
+
+        // Cells outside of the grid are given no space.
+        if cell.rowSpan == 0 || cell.colSpan == 0 {
+            err = cctx.ForwardResize(r, c, 0, 0)
+        } else {
+            top := rowOffsets[cell.row]
+            left := colOffsets[cell.col]
+
+            err = cctx.ForwardResize(r + top, c + left,
+                rowOffsets[cell.row + cell.rowSpan] - top,
+                colOffsets[cell.col + cell.colSpan] - left)
+        }
+
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// Events are forwarded to every child.
+func (ge *GridElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    if IsRoutedEvent(ev) {
+        return nil
+    }
+
+    for i := 0; i < ectx.NumChildren(); i++ {
+        cctx, _ := ectx.Child(i)
+
+        err := cctx.ForwardEvent(ev)
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// This clears the grid element's area. Children draw themselves afterwards.
+func (ge *GridElement) Draw(cv *Canvas) {
+    cv.Fill(' ', ge.style)
+}
@@ -0,0 +1,641 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Input Element --------------------------------------
+
+// An input element is a single line text box. While focused, the terminal
+// cursor is shown at the caret.
+//
+// Keys:
+//      Left/Right          Move the caret. (Ctrl or Alt jumps words)
+//      Home/End, Ctrl-A/E  Move to the start/end.
+//      Shift + movement    Select.
+//      Backspace/Delete    Delete. (Ctrl or Alt deletes words)
+//      Ctrl-W              Delete the word before the caret.
+//      Ctrl-U/Ctrl-K       Delete to the start/end.
+//      Up/Down             Move through history. (If enabled)
+//      Enter               Submit.
+//
+// Keys the input element does not use are left unhandled so they can
+// bubble up. (Tab moves focus as usual)
+
+type InputOptions struct {
+    placeholder string
+    placeholderStyle *tcell.Style
+    selectionStyle *tcell.Style
+
+    // When non-zero, every character is displayed as this rune.
+    mask rune
+
+    // History is only kept when historySize > 0.
+    historySize int
+    history []string
+
+    clearOnSubmit bool
+
+    onSubmit func (*ElementContext, string) error
+    onChange func (*ElementContext, string) error
+    onHistory func ([]string)
+}
+
+// By default, an input element has no placeholder, no mask and no history.
+func NewInputOptions() InputOptions {
+    return InputOptions{
+        placeholder: "",
+        placeholderStyle: nil,
+        selectionStyle: nil,
+        mask: 0,
+        historySize: 0,
+        history: nil,
+        clearOnSubmit: false,
+        onSubmit: nil,
+        onChange: nil,
+        onHistory: nil,
+    }
+}
+
+// Shown while the input is empty. By default it is drawn dimmed.
+func (io InputOptions) WithPlaceholder(p string) InputOptions {
+    io.placeholder = p
+    return io
+}
+
+func (io InputOptions) WithPlaceholderStyle(s tcell.Style) InputOptions {
+    io.placeholderStyle = &s
+    return io
+}
+
+// By default, selected text is drawn reversed.
+func (io InputOptions) WithSelectionStyle(s tcell.Style) InputOptions {
+    io.selectionStyle = &s
+    return io
+}
+
+// Used for passwords.
+func (io InputOptions) WithMask(m rune) InputOptions {
+    io.mask = m
+    return io
+}
+
+// Keeps the last size submitted lines, starting with the given lines.
+// (Oldest first)
+func (io InputOptions) WithHistory(size int, initial []string) InputOptions {
+    io.historySize = size
+    io.history = initial
+    return io
+}
+
+func (io InputOptions) WithClearOnSubmit() InputOptions {
+    io.clearOnSubmit = true
+    return io
+}
+
+// Called with the input's text when Enter is pressed.
+func (io InputOptions) OnSubmit(fn func (*ElementContext, string) error) InputOptions {
+    io.onSubmit = fn
+    return io
+}
+
+// Called with the input's text every time the user changes it.
+func (io InputOptions) OnChange(fn func (*ElementContext, string) error) InputOptions {
+    io.onChange = fn
+    return io
+}
+
+// Called with the whole history every time a line is added to it.
+// (For example, to save it)
+func (io InputOptions) OnHistory(fn func ([]string)) InputOptions {
+    io.onHistory = fn
+    return io
+}
+
+type InputElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    placeholderStyle tcell.Style
+    selectionStyle tcell.Style
+
+    opts InputOptions
+
+    text []glyph
+
+    // The caret is before text[caret].
+    caret int
+
+    // The other end of the selection, -1 if nothing is selected.
+    anchor int
+
+    // The first visible column.
+    colOff int
+
+    focused bool
+
+    // Set between a paste start and paste end event.
+    pasting bool
+
+    history []string
+
+    // Index into history of the line being shown. When equal to
+    // len(history), draft is shown.
+    histPos int
+    draft string
+}
+
+func NewInputElement(s tcell.Style, opts InputOptions) *InputElement {
+    ps := s.Dim(true)
+    if opts.placeholderStyle != nil {
+        ps = *opts.placeholderStyle
+    }
+
+    ss := s.Reverse(true)
+    if opts.selectionStyle != nil {
+        ss = *opts.selectionStyle
+    }
+
+    history := make([]string, 0, len(opts.history))
+    history = append(history, opts.history...)
+
+    return &InputElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        placeholderStyle: ps,
+        selectionStyle: ss,
+        opts: opts,
+        text: make([]glyph, 0),
+        caret: 0,
+        anchor: -1,
+        colOff: 0,
+        focused: false,
+        pasting: false,
+        history: history,
+        histPos: len(history),
+        draft: "",
+    }
+}
+
+func InputElementF(s tcell.Style, opts InputOptions) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewInputElement(s, opts))
+    }
+}
+
+func (ie *InputElement) AcceptsFocus() bool {
+    return true
+}
+
+func (ie *InputElement) GetText() string {
+    var sb strings.Builder
+    for _, g := range ie.text {
+        sb.WriteString(string(g.runes))
+    }
+
+    return sb.String()
+}
+
+// Replaces the text and moves the caret to the end.
+// NOTE: This does not call the change callback.
+func (ie *InputElement) SetText(ectx *ElementContext, t string) {
+    ie.setText(t)
+    ie.update(ectx)
+}
+
+func (ie *InputElement) setText(t string) {
+    ie.text = toInputGlyphs(t)
+    ie.caret = len(ie.text)
+    ie.anchor = -1
+}
+
+// Newlines and tabs become spaces.
+func toInputGlyphs(t string) []glyph {
+    return toGlyphs(PlainText(t, tcell.StyleDefault), 1, true)[0]
+}
+
+// An input element is always one row. It would like enough room for its
+// placeholder and the caret.
+func (ie *InputElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    return SizeHint{
+        PrefRows: 1,
+        PrefCols: toLine(PlainText(ie.opts.placeholder, ie.style), 1).width + 1,
+        MinRows: 1,
+        MinCols: 1,
+        MaxRows: 1,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (ie *InputElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := ie.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    ie.update(ectx)
+    return nil
+}
+
+// The number of columns the given glyph is displayed in.
+func (ie *InputElement) glyphWidth(g glyph) int {
+    if ie.opts.mask != 0 {
+        return 1
+    }
+
+    return g.width
+}
+
+// The column the given glyph index is displayed at. (Ignoring colOff)
+func (ie *InputElement) colOf(i int) int {
+    col := 0
+    for _, g := range ie.text[:i] {
+        col += ie.glyphWidth(g)
+    }
+
+    return col
+}
+
+// The glyph index nearest to the given column. (Ignoring colOff)
+func (ie *InputElement) indexAt(col int) int {
+    c := 0
+    for i, g := range ie.text {
+        w := ie.glyphWidth(g)
+        if col < c + (w + 1) / 2 {
+            return i
+        }
+
+        c += w
+    }
+
+    return len(ie.text)
+}
+
+// Returns the selected range of glyphs. ok is false if nothing is selected.
+func (ie *InputElement) selection() (int, int, bool) {
+    if ie.anchor == -1 || ie.anchor == ie.caret {
+        return 0, 0, false
+    }
+
+    return min(ie.anchor, ie.caret), max(ie.anchor, ie.caret), true
+}
+
+// Keeps the caret visible, then updates the cursor and redraws.
+func (ie *InputElement) update(ectx *ElementContext) {
+    view := ie.GetCols()
+    caretCol := ie.colOf(ie.caret)
+
+    if caretCol < ie.colOff {
+        ie.colOff = caretCol
+    }
+
+    if view > 0 && caretCol >= ie.colOff + view {
+        ie.colOff = caretCol - view + 1
+    }
+
+    // Don't leave empty space at the end if the text could fill it.
+    ie.colOff = max(min(ie.colOff, ie.colOf(len(ie.text)) + 1 - view), 0)
+
+    if ie.focused {
+        ectx.ShowCursor(0, caretCol - ie.colOff)
+    }
+
+    ectx.SetDrawFlag()
+}
+
+// Moves the caret. If extend is true, the selection is extended.
+func (ie *InputElement) moveTo(pos int, extend bool) {
+    if extend {
+        if ie.anchor == -1 {
+            ie.anchor = ie.caret
+        }
+    } else {
+        ie.anchor = -1
+    }
+
+    ie.caret = max(min(pos, len(ie.text)), 0)
+}
+
+// The start of the word before the caret.
+func (ie *InputElement) wordLeft() int {
+    i := ie.caret
+    for i > 0 && ie.text[i-1].isSpace() {
+        i--
+    }
+
+    for i > 0 && !ie.text[i-1].isSpace() {
+        i--
+    }
+
+    return i
+}
+
+// The end of the word after the caret.
+func (ie *InputElement) wordRight() int {
+    i := ie.caret
+    for i < len(ie.text) && ie.text[i].isSpace() {
+        i++
+    }
+
+    for i < len(ie.text) && !ie.text[i].isSpace() {
+        i++
+    }
+
+    return i
+}
+
+func (ie *InputElement) deleteRange(lo, hi int) {
+    ie.text = append(ie.text[:lo], ie.text[hi:]...)
+    ie.caret = lo
+    ie.anchor = -1
+}
+
+// Deletes the selection if there is one. Returns true if something
+// was deleted.
+func (ie *InputElement) deleteSelection() bool {
+    lo, hi, ok := ie.selection()
+    if ok {
+        ie.deleteRange(lo, hi)
+    }
+
+    return ok
+}
+
+// Deletes from the caret to the given position, unless there is a selection,
+// in which case the selection is deleted. pos is clamped to the text.
+func (ie *InputElement) deleteTo(pos int) {
+    if ie.deleteSelection() {
+        return
+    }
+
+    pos = max(min(pos, len(ie.text)), 0)
+
+    lo, hi := min(pos, ie.caret), max(pos, ie.caret)
+    if lo == hi {
+        return
+    }
+
+    ie.deleteRange(lo, hi)
+}
+
+func (ie *InputElement) insert(t string) {
+    ie.deleteSelection()
+
+    gs := toInputGlyphs(t)
+
+    text := make([]glyph, 0, len(ie.text) + len(gs))
+    text = append(text, ie.text[:ie.caret]...)
+    text = append(text, gs...)
+    text = append(text, ie.text[ie.caret:]...)
+
+    ie.text = text
+    ie.caret += len(gs)
+}
+
+// Shows the history entry at the given position.
+func (ie *InputElement) showHistory(pos int) {
+    if ie.histPos == len(ie.history) {
+        ie.draft = ie.GetText()
+    }
+
+    ie.histPos = pos
+
+    if pos == len(ie.history) {
+        ie.setText(ie.draft)
+    } else {
+        ie.setText(ie.history[pos])
+    }
+}
+
+func (ie *InputElement) submit(ectx *ElementContext) error {
+    text := ie.GetText()
+
+    if ie.opts.historySize > 0 && text != "" &&
+        (len(ie.history) == 0 || ie.history[len(ie.history) - 1] != text) {
+        ie.history = append(ie.history, text)
+
+        if len(ie.history) > ie.opts.historySize {
+            ie.history = ie.history[len(ie.history) - ie.opts.historySize:]
+        }
+
+        if ie.opts.onHistory != nil {
+            ie.opts.onHistory(ie.history)
+        }
+    }
+
+    ie.histPos = len(ie.history)
+    ie.draft = ""
+
+    if ie.opts.clearOnSubmit {
+        ie.setText("")
+    }
+
+    if ie.opts.onSubmit != nil {
+        return ie.opts.onSubmit(ectx, text)
+    }
+
+    return nil
+}
+
+// Performs the action of the given key.
+// handled is false if the input element does not use the key.
+func (ie *InputElement) handleKey(ectx *ElementContext, ev *tcell.EventKey) (bool, error) {
+    mods := ev.Modifiers()
+    shift := mods & tcell.ModShift != 0
+    word := mods & (tcell.ModCtrl | tcell.ModAlt) != 0
+
+    before := ie.GetText()
+
+    switch ev.Key() {
+    case tcell.KeyRune:
+        switch {
+        case mods & tcell.ModAlt == 0:
+            ie.insert(string(ev.Rune()))
+        case ev.Rune() == 'b':
+            ie.moveTo(ie.wordLeft(), false)
+        case ev.Rune() == 'f':
+            ie.moveTo(ie.wordRight(), false)
+        default:
+            return false, nil
+        }
+
+    case tcell.KeyEnter:
+        // Pasted newlines do not submit.
+        if ie.pasting {
+            ie.insert(" ")
+            break
+        }
+
+        ie.update(ectx)
+        return true, ie.submit(ectx)
+
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if word {
+            ie.deleteTo(ie.wordLeft())
+        } else {
+            ie.deleteTo(ie.caret - 1)
+        }
+
+    case tcell.KeyDelete:
+        if word {
+            ie.deleteTo(ie.wordRight())
+        } else {
+            ie.deleteTo(ie.caret + 1)
+        }
+
+    case tcell.KeyCtrlD:
+        ie.deleteTo(ie.caret + 1)
+    case tcell.KeyCtrlW:
+        ie.deleteTo(ie.wordLeft())
+    case tcell.KeyCtrlU:
+        ie.deleteTo(0)
+    case tcell.KeyCtrlK:
+        ie.deleteTo(len(ie.text))
+
+    case tcell.KeyLeft:
+        lo, _, ok := ie.selection()
+        switch {
+        case word:
+            ie.moveTo(ie.wordLeft(), shift)
+        case ok && !shift:
+            ie.moveTo(lo, false)
+        default:
+            ie.moveTo(ie.caret - 1, shift)
+        }
+
+    case tcell.KeyRight:
+        _, hi, ok := ie.selection()
+        switch {
+        case word:
+            ie.moveTo(ie.wordRight(), shift)
+        case ok && !shift:
+            ie.moveTo(hi, false)
+        default:
+            ie.moveTo(ie.caret + 1, shift)
+        }
+
+    case tcell.KeyHome, tcell.KeyCtrlA:
+        ie.moveTo(0, shift)
+    case tcell.KeyEnd, tcell.KeyCtrlE:
+        ie.moveTo(len(ie.text), shift)
+
+    case tcell.KeyUp:
+        if ie.opts.historySize == 0 {
+            return false, nil
+        }
+
+        if ie.histPos > 0 {
+            ie.showHistory(ie.histPos - 1)
+        }
+
+    case tcell.KeyDown:
+        if ie.opts.historySize == 0 {
+            return false, nil
+        }
+
+        if ie.histPos < len(ie.history) {
+            ie.showHistory(ie.histPos + 1)
+        }
+
+    default:
+        return false, nil
+    }
+
+    ie.update(ectx)
+
+    after := ie.GetText()
+    if after != before && ie.opts.onChange != nil {
+        return true, ie.opts.onChange(ectx, after)
+    }
+
+    return true, nil
+}
+
+func (ie *InputElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    switch tev := ev.(type) {
+    case *FocusEvent:
+        ie.focused = true
+        ie.update(ectx)
+
+    case *BlurEvent:
+        ie.focused = false
+        ie.pasting = false
+        ectx.HideCursor()
+        ectx.SetDrawFlag()
+
+    case *tcell.EventPaste:
+        ie.pasting = tev.Start()
+        ectx.MarkHandled()
+
+    case *MouseEvent:
+        x, _ := tev.Position()
+        pos := ie.indexAt(x + ie.colOff)
+
+        switch tev.Action() {
+        case MOUSE_PRESS:
+            ie.moveTo(pos, false)
+            ectx.MarkHandled()
+
+            err := ectx.Focus()
+            if err != nil {
+                return err
+            }
+
+        case MOUSE_DRAG:
+            ie.moveTo(pos, true)
+            ectx.MarkHandled()
+
+        default:
+            return nil
+        }
+
+        ie.update(ectx)
+
+    case *tcell.EventKey:
+        if ectx.EventHandled() {
+            return nil
+        }
+
+        handled, err := ie.handleKey(ectx, tev)
+        if handled {
+            ectx.MarkHandled()
+        }
+
+        return err
+    }
+
+    return nil
+}
+
+func (ie *InputElement) Draw(cv *Canvas) {
+    cv.Fill(' ', ie.style)
+
+    if len(ie.text) == 0 {
+        cv.PrintStyled(0, 0, ie.opts.placeholder, ie.placeholderStyle)
+        return
+    }
+
+    lo, hi, sel := ie.selection()
+
+    c := -ie.colOff
+    for i, g := range ie.text {
+        w := ie.glyphWidth(g)
+
+        style := ie.style
+        if sel && lo <= i && i < hi {
+            style = ie.selectionStyle
+        }
+
+        if c >= 0 && c + w <= cv.Cols() {
+            if ie.opts.mask != 0 {
+                cv.SetCellAt(0, c, ie.opts.mask, style)
+            } else {
+                cv.SetContentAt(0, c, g.runes[0], g.runes[1:], style)
+            }
+        }
+
+        c += w
+    }
+}
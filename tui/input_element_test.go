@@ -0,0 +1,98 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+type keyPress struct {
+    k tcell.Key
+    mod tcell.ModMask
+}
+
+// Returns a harness with a focused input element as its root.
+func newInputHarness(t *testing.T, opts tui.InputOptions) (*tuitest.Harness, *tui.InputElement) {
+    ie := tui.NewInputElement(plain, opts)
+
+    h := tuitest.New(t, 1, 20, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(ie)
+    })
+
+    h.Click(0, 0)
+    return h, ie
+}
+
+// Deleting past either end of the text does nothing.
+func TestInputDeleteAtEdges(t *testing.T) {
+    backspace := keyPress{tcell.KeyBackspace2, tcell.ModNone}
+    wordBackspace := keyPress{tcell.KeyBackspace2, tcell.ModCtrl}
+    del := keyPress{tcell.KeyDelete, tcell.ModNone}
+    wordDel := keyPress{tcell.KeyDelete, tcell.ModCtrl}
+    ctrlD := keyPress{tcell.KeyCtrlD, tcell.ModCtrl}
+    home := keyPress{tcell.KeyHome, tcell.ModNone}
+
+    cases := []struct {
+        name string
+        typed string
+        keys []keyPress
+        expected string
+    }{
+        {"backspace empty", "", []keyPress{backspace}, ""},
+        {"word backspace empty", "", []keyPress{wordBackspace}, ""},
+        {"delete empty", "", []keyPress{del}, ""},
+        {"word delete empty", "", []keyPress{wordDel}, ""},
+        {"ctrl-d empty", "", []keyPress{ctrlD}, ""},
+        {"backspace at start", "abc", []keyPress{home, backspace}, "abc"},
+        {"word backspace at start", "abc", []keyPress{home, wordBackspace}, "abc"},
+        {"delete at end", "abc", []keyPress{del}, "abc"},
+        {"word delete at end", "abc", []keyPress{wordDel}, "abc"},
+        {"ctrl-d at end", "abc", []keyPress{ctrlD, ctrlD}, "abc"},
+        {"backspace past start", "ab", []keyPress{backspace, backspace, backspace}, ""},
+        {"delete past end", "ab", []keyPress{home, del, del, del}, ""},
+        {"backspace", "abc", []keyPress{backspace}, "ab"},
+        {"delete", "abc", []keyPress{home, del}, "bc"},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            h, ie := newInputHarness(t, tui.NewInputOptions())
+
+            h.Type(tc.typed)
+            for _, kp := range tc.keys {
+                h.Key(kp.k, kp.mod)
+            }
+
+            if actual := ie.GetText(); actual != tc.expected {
+                t.Errorf("expected %q, got %q", tc.expected, actual)
+            }
+        })
+    }
+}
+
+func TestInputTypeAndSubmit(t *testing.T) {
+    submitted := ""
+
+    h, ie := newInputHarness(t, tui.NewInputOptions().
+        WithClearOnSubmit().
+        OnSubmit(func (ectx *tui.ElementContext, text string) error {
+            submitted = text
+            return nil
+        }))
+
+    h.Type("hello")
+    h.AssertGolden("input_typed")
+
+    // The cursor follows the caret.
+    if x, y, visible := h.Screen().GetCursor(); x != 5 || y != 0 || !visible {
+        t.Errorf("expected the cursor at (5, 0), got (%d, %d) visible=%t", x, y, visible)
+    }
+
+    h.Key(tcell.KeyEnter, tcell.ModNone)
+
+    if submitted != "hello" || ie.GetText() != "" {
+        t.Errorf("expected %q submitted and cleared, got %q and %q", "hello", submitted, ie.GetText())
+    }
+}
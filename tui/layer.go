@@ -0,0 +1,422 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Layers --------------------------------------
+
+// Layers are element trees drawn above the root's tree. They are used for
+// dialogs, dropdown menus, popups and toasts.
+//
+// Layers are ordered by their z value, layers with equal z values are
+// ordered by when they were pushed. Later layers are drawn on top and
+// are hit tested first.
+//
+// A modal layer captures all input. Elements below the topmost modal layer
+// can't be focused, clicked or hovered.
+//
+// NOTE: A layer's root is laid out at its preferred size, then placed
+// according to its anchor.
+
+// Where a layer is placed relative to its anchor rectangle.
+type Placement int
+
+const (
+    // Centered on the anchor.
+    PLACE_CENTER Placement = iota
+
+    // Covering the anchor exactly.
+    PLACE_FILL
+
+    // Inside the anchor, against one of its corners.
+    PLACE_TOP_LEFT
+    PLACE_TOP_RIGHT
+    PLACE_BOTTOM_LEFT
+    PLACE_BOTTOM_RIGHT
+
+    // Outside the anchor, left aligned with it.
+    // If there is not enough room on the given side, the other side is used.
+    PLACE_BELOW
+    PLACE_ABOVE
+)
+
+// An anchor is either the screen or the on screen rectangle of an element.
+type Anchor struct {
+    target ElementID
+    placement Placement
+
+    // Added to the layer's position after placement.
+    dr, dc int
+}
+
+func ScreenAnchor(p Placement) Anchor {
+    return Anchor{
+        target: NULL_EID,
+        placement: p,
+        dr: 0,
+        dc: 0,
+    }
+}
+
+// If the target element is deregistered, the layer is anchored to
+// the screen instead.
+func ElementAnchor(eid ElementID, p Placement) Anchor {
+    return Anchor{
+        target: eid,
+        placement: p,
+        dr: 0,
+        dc: 0,
+    }
+}
+
+func (a Anchor) Offset(dr, dc int) Anchor {
+    a.dr = dr
+    a.dc = dc
+    return a
+}
+
+type LayerSpec struct {
+    anchor Anchor
+    z int
+    modal bool
+
+    // If true, everything below the layer is drawn dimmed.
+    backdrop bool
+}
+
+// By default, a layer has z value 0, is not modal and has no backdrop.
+func NewLayerSpec(a Anchor) LayerSpec {
+    return LayerSpec{
+        anchor: a,
+        z: 0,
+        modal: false,
+        backdrop: false,
+    }
+}
+
+func (ls LayerSpec) WithZ(z int) LayerSpec {
+    ls.z = z
+    return ls
+}
+
+func (ls LayerSpec) Modal() LayerSpec {
+    ls.modal = true
+    return ls
+}
+
+func (ls LayerSpec) WithBackdrop() LayerSpec {
+    ls.backdrop = true
+    return ls
+}
+
+type layer struct {
+    rootID ElementID
+    spec LayerSpec
+
+    // Where the layer's root was last placed on screen.
+    rect Rect
+
+    // The layout generation, screen and anchor rectangle the layer was last
+    // placed with. Outside of a layout, a layer is only placed again once
+    // one of these changes.
+    placedGen int
+    placedScreen Rect
+    placedAnchor Rect
+
+    // What was focused when the layer was pushed.
+    prevFocusID ElementID
+}
+
+// Returns the index of the given layer root, or -1.
+func (env *Environment) layerIndex(eid ElementID) int {
+    for i, l := range env.layers {
+        if l.rootID == eid {
+            return i
+        }
+    }
+
+    return -1
+}
+
+// Returns the index of the topmost modal layer, or -1.
+func (env *Environment) modalIndex() int {
+    for i := len(env.layers) - 1; i >= 0; i-- {
+        if env.layers[i].spec.modal {
+            return i
+        }
+    }
+
+    return -1
+}
+
+func (env *Environment) NumLayers() int {
+    return len(env.layers)
+}
+
+// Returns the roots of every tree in draw order.
+// (The root, followed by each layer)
+func (env *Environment) treeRoots() []ElementID {
+    roots := make([]ElementID, 0, len(env.layers) + 1)
+
+    if env.rootID != NULL_EID {
+        roots = append(roots, env.rootID)
+    }
+
+    for _, l := range env.layers {
+        roots = append(roots, l.rootID)
+    }
+
+    return roots
+}
+
+// Returns the roots of the trees which can receive input in draw order.
+// (Nothing below the topmost modal layer)
+func (env *Environment) activeRoots() []ElementID {
+    m := env.modalIndex()
+    if m == -1 {
+        return env.treeRoots()
+    }
+
+    roots := make([]ElementID, 0, len(env.layers) - m)
+    for _, l := range env.layers[m:] {
+        roots = append(roots, l.rootID)
+    }
+
+    return roots
+}
+
+// Returns the on screen rectangle of the given anchor.
+func (env *Environment) anchorRect(a Anchor) Rect {
+    if a.target != NULL_EID {
+        if _, err := env.getEnvEntry(a.target); err == nil {
+            return env.screenRect(a.target)
+        }
+    }
+
+    sCols, sRows := env.screen.Size()
+    return NewRect(0, 0, sRows, sCols)
+}
+
+// Returns where a layer with the given size should be placed.
+func (env *Environment) placement(spec LayerSpec, rows, cols int) Rect {
+    sCols, sRows := env.screen.Size()
+    anchor := env.anchorRect(spec.anchor)
+
+    var r, c int
+
+    switch spec.anchor.placement {
+    case PLACE_FILL:
+        r, c = anchor.R, anchor.C
+        rows, cols = anchor.Rows, anchor.Cols
+    case PLACE_TOP_LEFT:
+        r, c = anchor.R, anchor.C
+    case PLACE_TOP_RIGHT:
+        r, c = anchor.R, anchor.C + anchor.Cols - cols
+    case PLACE_BOTTOM_LEFT:
+        r, c = anchor.R + anchor.Rows - rows, anchor.C
+    case PLACE_BOTTOM_RIGHT:
+        r, c = anchor.R + anchor.Rows - rows, anchor.C + anchor.Cols - cols
+    case PLACE_BELOW:
+        r, c = anchor.R + anchor.Rows, anchor.C
+        if r + rows > sRows && anchor.R - rows >= 0 {
+            r = anchor.R - rows
+        }
+    case PLACE_ABOVE:
+        r, c = anchor.R - rows, anchor.C
+        if r < 0 && anchor.R + anchor.Rows + rows <= sRows {
+            r = anchor.R + anchor.Rows
+        }
+    default:
+        r = anchor.R + (anchor.Rows - rows) / 2
+        c = anchor.C + (anchor.Cols - cols) / 2
+    }
+
+    // The layer is always kept on screen.
+    rows = max(min(rows, sRows), 0)
+    cols = max(min(cols, sCols), 0)
+    r = max(min(r + spec.anchor.dr, sRows - rows), 0)
+    c = max(min(c + spec.anchor.dc, sCols - cols), 0)
+
+    return NewRect(r, c, rows, cols)
+}
+
+// Measures and places every layer. When force is false, only layers which
+// may have moved are measured, and only those whose placement changed are
+// resized.
+func (env *Environment) placeLayers(force bool) error {
+    cols, rows := env.screen.Size()
+    screen := NewRect(0, 0, rows, cols)
+
+    for _, l := range env.layers {
+        anchor := env.anchorRect(l.spec.anchor)
+
+        if !force && l.placedGen == env.layoutGen &&
+            l.placedScreen == screen && l.placedAnchor == anchor {
+            continue
+        }
+
+        l.placedGen = env.layoutGen
+        l.placedScreen = screen
+        l.placedAnchor = anchor
+
+        hint, err := env.Measure(l.rootID, Constraints{MaxRows: rows, MaxCols: cols})
+        if err != nil {
+            return fmt.Errorf("placeLayers: %w", err)
+        }
+
+        rect := env.placement(l.spec, hint.PrefRows, hint.PrefCols)
+        if !force && rect == l.rect {
+            continue
+        }
+
+        l.rect = rect
+
+        err = env.ForwardResize(l.rootID, rect.R, rect.C, rect.Rows, rect.Cols)
+        if err != nil {
+            return fmt.Errorf("placeLayers: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// Pushes the given element as the root of a new layer.
+// The element must not have a parent, and must not be the root
+// or already be a layer.
+//
+// When a modal layer is pushed, focus moves to the first element of the
+// layer which accepts focus.
+func (env *Environment) PushLayer(eid ElementID, spec LayerSpec) error {
+    ee, err := env.getEnvEntry(eid)
+    if err != nil {
+        return fmt.Errorf("PushLayer: %w", err)
+    }
+
+    if ee.ectx.parentID != NULL_EID {
+        return fmt.Errorf("PushLayer: Element has parent: %d, %d", ee.ectx.parentID, eid)
+    }
+
+    if eid == env.rootID || env.layerIndex(eid) != -1 {
+        return fmt.Errorf("PushLayer: Element already root or layer: %d", eid)
+    }
+
+    l := &layer{
+        rootID: eid,
+        spec: spec,
+        rect: Rect{},
+        placedGen: -1,
+        placedScreen: Rect{},
+        placedAnchor: Rect{},
+        prevFocusID: env.focusID,
+    }
+
+    // Insert after every layer with a smaller or equal z value.
+    i := len(env.layers)
+    for ; i > 0 && env.layers[i-1].spec.z > spec.z; i-- {
+    }
+
+    env.layers = append(env.layers, nil)
+    copy(env.layers[i+1:], env.layers[i:])
+    env.layers[i] = l
+
+    err = env.placeLayers(true)
+    if err != nil {
+        return fmt.Errorf("PushLayer: %w", err)
+    }
+
+    if spec.backdrop {
+        env.Invalidate()
+    }
+
+    if spec.modal && (env.focusID == NULL_EID || !env.inTree(env.focusID)) {
+        err = env.FocusNext()
+        if err != nil {
+            return fmt.Errorf("PushLayer: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// Removes the layer with the given root. The root stays registered.
+//
+// If focus was inside the layer, it returns to whatever was focused
+// when the layer was pushed.
+func (env *Environment) RemoveLayer(eid ElementID) error {
+    i := env.layerIndex(eid)
+    if i == -1 {
+        return fmt.Errorf("RemoveLayer: Element is not a layer: %d", eid)
+    }
+
+    l := env.layers[i]
+    env.layers = append(env.layers[:i], env.layers[i+1:]...)
+
+    env.damageTree(eid)
+    if l.spec.backdrop {
+        env.Invalidate()
+    }
+
+    if env.focusID != NULL_EID && env.inTree(env.focusID) {
+        return nil
+    }
+
+    err := env.Blur()
+    if err != nil {
+        return fmt.Errorf("RemoveLayer: %w", err)
+    }
+
+    prev := l.prevFocusID
+    if _, err := env.getEnvEntry(prev); err == nil &&
+        env.inTree(prev) && env.acceptsFocus(prev) {
+        err = env.Focus(prev)
+        if err != nil {
+            return fmt.Errorf("RemoveLayer: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// Removes the topmost layer, returning its root.
+func (env *Environment) PopLayer() (ElementID, error) {
+    if len(env.layers) == 0 {
+        return NULL_EID, fmt.Errorf("PopLayer: No layers")
+    }
+
+    eid := env.layers[len(env.layers) - 1].rootID
+
+    err := env.RemoveLayer(eid)
+    if err != nil {
+        return NULL_EID, fmt.Errorf("PopLayer: %w", err)
+    }
+
+    return eid, nil
+}
+
+// Draws every cell inside a damaged region dimmed.
+// (See damage.go)
+func (env *Environment) dimBackdrop() {
+    for _, d := range env.damage {
+        for r := d.R; r < d.R + d.Rows; r++ {
+            for c := d.C; c < d.C + d.Cols; c++ {
+                mainc, combc, style, _ := env.screen.GetContent(c, r)
+                env.screen.SetContent(c, r, mainc, combc, style.Dim(true))
+            }
+        }
+    }
+}
+
+// Sends the given event to the root of every tree.
+func (env *Environment) broadcast(ev tcell.Event) error {
+    for _, eid := range env.treeRoots() {
+        err := env.ForwardEvent(eid, ev)
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Counts how many times it is measured.
+type measureCounter struct {
+    *DefaultElement
+
+    measures int
+}
+
+func (mc *measureCounter) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    mc.measures++
+
+    return SizeHint{
+        PrefRows: 2,
+        PrefCols: 4,
+        MinRows: 0,
+        MinCols: 0,
+        MaxRows: UNBOUNDED,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func TestLayerPlacedOnlyWhenNeeded(t *testing.T) {
+    s := tcell.NewSimulationScreen("UTF-8")
+    err := s.Init()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    t.Cleanup(s.Fini)
+    s.SetSize(20, 10)
+
+    env := NewEnvironment(s, UNLIMITED_CAPACITY, 10 * time.Millisecond)
+
+    rootID, _ := env.Register(NewDefaultElement())
+    err = env.MakeRoot(rootID)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // The layer is anchored to an element which is moved by hand.
+    anchorID, _ := env.Register(NewDefaultElement())
+    env.Attach(rootID, anchorID)
+
+    mc := &measureCounter{DefaultElement: NewDefaultElement()}
+    layerID, _ := env.Register(mc)
+
+    err = env.PushLayer(layerID, NewLayerSpec(ElementAnchor(anchorID, PLACE_BELOW)))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    render := func () {
+        err := env.Render()
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    render()
+    measures := mc.measures
+
+    for i := 0; i < 5; i++ {
+        render()
+    }
+
+    if mc.measures != measures {
+        t.Fatalf("idle frames measured the layer %d times", mc.measures - measures)
+    }
+
+    env.RequestLayout()
+    render()
+
+    if mc.measures == measures {
+        t.Fatal("layer not measured after a layout")
+    }
+
+    // Moving the anchor without a layout still moves the layer.
+    err = env.ForwardResize(anchorID, 3, 5, 1, 1)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    render()
+
+    ectx, _ := env.GetElementContext(layerID)
+    if ectx.r != 4 || ectx.c != 5 {
+        t.Errorf("expected the layer at (4, 5), got (%d, %d)", ectx.r, ectx.c)
+    }
+}
@@ -0,0 +1,453 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- List Element --------------------------------------
+
+// A list element shows the items of a list source, one per row, with one
+// item selected.
+//
+// Only the visible items are ever asked for, so a list can be as long as
+// its source likes. The list scrolls by keeping an offset into the items,
+// like a viewport does.
+//
+// Keys:
+//      Up/Down             Move the selection.
+//      PageUp/PageDown     Move the selection by a page.
+//      Home/End            Select the first/last item.
+//      Enter               Activate the selected item.
+//      Anything typed      Select the next item starting with what was
+//                          typed. (Type-ahead)
+//
+// Clicking an item selects it, clicking the selected item activates it.
+
+// A list source provides the items of a list. Item is only called for
+// items which are displayed or searched.
+type ListSource interface {
+    Len() int
+    Item(i int) StyledText
+}
+
+// A list source of plain strings.
+type StringListSource struct {
+    items []string
+    style tcell.Style
+}
+
+func NewStringListSource(items []string, s tcell.Style) *StringListSource {
+    return &StringListSource{
+        items: items,
+        style: s,
+    }
+}
+
+func (sls *StringListSource) Len() int {
+    return len(sls.items)
+}
+
+func (sls *StringListSource) Item(i int) StyledText {
+    return PlainText(sls.items[i], sls.style)
+}
+
+// Type-ahead starts over after this long without typing.
+const typeAheadTimeout = time.Second
+
+// At most this many items are looked at when measuring a list.
+const listMeasureLimit = 256
+
+type ListOptions struct {
+    selectedStyle *tcell.Style
+
+    onSelect func (*ElementContext, int) error
+    onActivate func (*ElementContext, int) error
+}
+
+func NewListOptions() ListOptions {
+    return ListOptions{
+        selectedStyle: nil,
+        onSelect: nil,
+        onActivate: nil,
+    }
+}
+
+// By default, the selected item is drawn reversed.
+func (lo ListOptions) WithSelectedStyle(s tcell.Style) ListOptions {
+    lo.selectedStyle = &s
+    return lo
+}
+
+// Called with the index of the newly selected item every time the user
+// moves the selection.
+func (lo ListOptions) OnSelect(fn func (*ElementContext, int) error) ListOptions {
+    lo.onSelect = fn
+    return lo
+}
+
+// Called with the index of the selected item when it is activated.
+func (lo ListOptions) OnActivate(fn func (*ElementContext, int) error) ListOptions {
+    lo.onActivate = fn
+    return lo
+}
+
+type ListElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    selectedStyle tcell.Style
+
+    opts ListOptions
+
+    src ListSource
+
+    // -1 when the list is empty.
+    selected int
+
+    // The first visible item.
+    rowOff int
+
+    // What has been typed so far, and when it was last typed to.
+    typed string
+    typedAt time.Time
+
+    // Set when a press lands on the item which was already selected.
+    pressedSelected bool
+}
+
+func NewListElement(s tcell.Style, src ListSource, opts ListOptions) *ListElement {
+    ss := s.Reverse(true)
+    if opts.selectedStyle != nil {
+        ss = *opts.selectedStyle
+    }
+
+    selected := -1
+    if src.Len() > 0 {
+        selected = 0
+    }
+
+    return &ListElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        selectedStyle: ss,
+        opts: opts,
+        src: src,
+        selected: selected,
+        rowOff: 0,
+        typed: "",
+        typedAt: time.Time{},
+        pressedSelected: false,
+    }
+}
+
+func ListElementF(s tcell.Style, src ListSource, opts ListOptions) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewListElement(s, src, opts))
+    }
+}
+
+func (le *ListElement) AcceptsFocus() bool {
+    return true
+}
+
+func (le *ListElement) GetSource() ListSource {
+    return le.src
+}
+
+// Replaces the source and selects its first item.
+func (le *ListElement) SetSource(ectx *ElementContext, src ListSource) {
+    le.src = src
+    le.selected = -1
+    le.rowOff = 0
+
+    if src.Len() > 0 {
+        le.selected = 0
+    }
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+}
+
+// Should be called after the source's items change. The selection is kept
+// where possible.
+func (le *ListElement) Refresh(ectx *ElementContext) {
+    n := le.src.Len()
+    le.selected = min(max(le.selected, 0), n - 1)
+    le.scrollToSelected()
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+}
+
+// Returns -1 if the list is empty.
+func (le *ListElement) GetSelected() int {
+    return le.selected
+}
+
+// Selects the given item.
+// NOTE: This does not call the select callback.
+func (le *ListElement) SetSelected(ectx *ElementContext, i int) {
+    le.moveSelection(i)
+    ectx.SetDrawFlag()
+}
+
+// Moves the selection, returns true if it changed.
+func (le *ListElement) moveSelection(i int) bool {
+    n := le.src.Len()
+    if n == 0 {
+        return false
+    }
+
+    i = max(min(i, n - 1), 0)
+    if i == le.selected {
+        return false
+    }
+
+    le.selected = i
+    le.scrollToSelected()
+
+    return true
+}
+
+// Moves the selection and calls the select callback if it changed.
+func (le *ListElement) selectItem(ectx *ElementContext, i int) error {
+    if !le.moveSelection(i) {
+        return nil
+    }
+
+    ectx.SetDrawFlag()
+
+    if le.opts.onSelect != nil {
+        return le.opts.onSelect(ectx, le.selected)
+    }
+
+    return nil
+}
+
+func (le *ListElement) activate(ectx *ElementContext) error {
+    if le.selected == -1 || le.opts.onActivate == nil {
+        return nil
+    }
+
+    return le.opts.onActivate(ectx, le.selected)
+}
+
+// Keeps the offset within the items.
+func (le *ListElement) clampOffset() {
+    le.rowOff = max(min(le.rowOff, le.src.Len() - le.GetRows()), 0)
+}
+
+func (le *ListElement) scrollToSelected() {
+    if le.selected < le.rowOff {
+        le.rowOff = le.selected
+    }
+
+    if le.GetRows() > 0 && le.selected >= le.rowOff + le.GetRows() {
+        le.rowOff = le.selected - le.GetRows() + 1
+    }
+
+    le.clampOffset()
+}
+
+// Returns the next item (starting at start and wrapping around) whose
+// text starts with prefix, ignoring case. ok is false if there is none.
+func (le *ListElement) findPrefix(start int, prefix string) (int, bool) {
+    n := le.src.Len()
+    prefix = strings.ToLower(prefix)
+
+    for k := 0; k < n; k++ {
+        i := (start + k) % n
+        if strings.HasPrefix(strings.ToLower(le.src.Item(i).String()), prefix) {
+            return i, true
+        }
+    }
+
+    return 0, false
+}
+
+func (le *ListElement) typeAhead(ectx *ElementContext, ev *tcell.EventKey) error {
+    if ev.When().Sub(le.typedAt) > typeAheadTimeout {
+        le.typed = ""
+    }
+
+    le.typed += string(ev.Rune())
+    le.typedAt = ev.When()
+
+    prefix := le.typed
+
+    // Typing the same letter over and over cycles through the items
+    // starting with it.
+    first := string(ev.Rune())
+    if strings.Count(prefix, first) * len(first) == len(prefix) {
+        prefix = first
+    }
+
+    // A new search starts after the selected item.
+    start := max(le.selected, 0)
+    if len(prefix) == len(first) {
+        start++
+    }
+
+    i, ok := le.findPrefix(start, prefix)
+    if !ok {
+        return nil
+    }
+
+    return le.selectItem(ectx, i)
+}
+
+// A list would like to show all of its items. Only the first few items
+// are measured.
+func (le *ListElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    n := le.src.Len()
+
+    cols := 0
+    for i := 0; i < min(n, listMeasureLimit); i++ {
+        cols = max(cols, toLine(le.src.Item(i), NewTextFormat().tabWidth).width)
+    }
+
+    return SizeHint{
+        PrefRows: n,
+        PrefCols: cols,
+        MinRows: min(n, 1),
+        MinCols: 0,
+        MaxRows: UNBOUNDED,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (le *ListElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := le.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    le.scrollToSelected()
+    return nil
+}
+
+// Performs the action of the given key.
+// handled is false if the list does not use the key.
+func (le *ListElement) handleKey(ectx *ElementContext, ev *tcell.EventKey) (bool, error) {
+    page := max(le.GetRows() - 1, 1)
+
+    switch ev.Key() {
+    case tcell.KeyUp:
+        return true, le.selectItem(ectx, le.selected - 1)
+    case tcell.KeyDown:
+        return true, le.selectItem(ectx, le.selected + 1)
+    case tcell.KeyPgUp:
+        return true, le.selectItem(ectx, le.selected - page)
+    case tcell.KeyPgDn:
+        return true, le.selectItem(ectx, le.selected + page)
+    case tcell.KeyHome:
+        return true, le.selectItem(ectx, 0)
+    case tcell.KeyEnd:
+        return true, le.selectItem(ectx, le.src.Len() - 1)
+    case tcell.KeyEnter:
+        return true, le.activate(ectx)
+
+    case tcell.KeyRune:
+        if ev.Modifiers() & (tcell.ModCtrl | tcell.ModAlt) != 0 {
+            return false, nil
+        }
+
+        return true, le.typeAhead(ectx, ev)
+    }
+
+    return false, nil
+}
+
+func (le *ListElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    if ectx.EventHandled() {
+        return nil
+    }
+
+    switch tev := ev.(type) {
+    case *MouseEvent:
+        return le.handleMouse(ectx, tev)
+
+    case *tcell.EventKey:
+        handled, err := le.handleKey(ectx, tev)
+        if handled {
+            ectx.MarkHandled()
+        }
+
+        return err
+    }
+
+    return nil
+}
+
+func (le *ListElement) handleMouse(ectx *ElementContext, ev *MouseEvent) error {
+    _, y := ev.Position()
+    i := le.rowOff + y
+
+    switch ev.Action() {
+    case MOUSE_PRESS:
+        ectx.MarkHandled()
+
+        err := ectx.Focus()
+        if err != nil {
+            return err
+        }
+
+        if i >= le.src.Len() {
+            return nil
+        }
+
+        le.pressedSelected = i == le.selected
+        return le.selectItem(ectx, i)
+
+    case MOUSE_CLICK:
+        ectx.MarkHandled()
+
+        if le.pressedSelected && i == le.selected {
+            return le.activate(ectx)
+        }
+
+    case MOUSE_WHEEL:
+        buttons := ev.Buttons()
+
+        switch {
+        case buttons & tcell.WheelUp != 0:
+            le.rowOff -= wheelRows
+        case buttons & tcell.WheelDown != 0:
+            le.rowOff += wheelRows
+        default:
+            return nil
+        }
+
+        // Scrolling does not move the selection.
+        ectx.MarkHandled()
+        le.clampOffset()
+        ectx.SetDrawFlag()
+    }
+
+    return nil
+}
+
+func (le *ListElement) Draw(cv *Canvas) {
+    cv.Fill(' ', le.style)
+
+    n := le.src.Len()
+    tabWidth := NewTextFormat().tabWidth
+
+    for r := 0; r < cv.Rows() && le.rowOff + r < n; r++ {
+        i := le.rowOff + r
+        line := truncateLine(toLine(le.src.Item(i), tabWidth), cv.Cols(), false)
+
+        if i == le.selected {
+            cv.FillRect(r, 0, 1, cv.Cols(), ' ', le.selectedStyle)
+
+            for j := range line.glyphs {
+                line.glyphs[j].style = le.selectedStyle
+            }
+        }
+
+        drawLine(cv, r, 0, line)
+    }
+}
@@ -0,0 +1,66 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestListEmptySource(t *testing.T) {
+    calls := 0
+    count := func (ectx *tui.ElementContext, i int) error {
+        calls++
+        return nil
+    }
+
+    le := tui.NewListElement(plain, tui.NewStringListSource(nil, plain),
+        tui.NewListOptions().OnSelect(count).OnActivate(count))
+
+    testEmptyNavigation(t, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(le)
+    }, func () bool {
+        return le.GetSelected() != -1
+    }, &calls)
+}
+
+func TestListNavigation(t *testing.T) {
+    selected := -1
+    le := tui.NewListElement(plain, tui.NewStringListSource([]string{"ab", "cd", "ce", "ef"}, plain),
+        tui.NewListOptions().OnSelect(func (ectx *tui.ElementContext, i int) error {
+            selected = i
+            return nil
+        }))
+
+    h := newFocusedHarness(t, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(le)
+    }, 0, 0)
+
+    steps := []struct {
+        k tcell.Key
+        typed string
+        expected int
+    }{
+        {tcell.KeyDown, "", 1},
+        {tcell.KeyEnd, "", 3},
+        {tcell.KeyDown, "", 3},
+        {tcell.KeyHome, "", 0},
+        {tcell.KeyUp, "", 0},
+        {tcell.KeyRune, "c", 1},
+        {tcell.KeyRune, "e", 2},
+    }
+
+    for _, step := range steps {
+        if step.k == tcell.KeyRune {
+            h.Type(step.typed)
+        } else {
+            h.Key(step.k, tcell.ModNone)
+        }
+
+        if le.GetSelected() != step.expected || selected != step.expected {
+            t.Fatalf("expected %d selected, got %d (callback saw %d)",
+                step.expected, le.GetSelected(), selected)
+        }
+    }
+}
+
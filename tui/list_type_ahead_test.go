@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// A typed key. When late is set, the key is typed after the type-ahead
+// timeout has passed.
+type typedKey struct {
+    r rune
+    late bool
+    expected int
+}
+
+func TestListTypeAhead(t *testing.T) {
+    items := []string{"apple", "avocado", "banana", "Blueberry", "cherry"}
+
+    cases := []struct {
+        name string
+        keys []typedKey
+    }{
+        {"repeat cycles", []typedKey{{'b', false, 2}, {'b', false, 3}, {'b', false, 2}}},
+        {"repeat wraps", []typedKey{{'a', false, 1}, {'a', false, 0}}},
+        {"prefix", []typedKey{{'b', false, 2}, {'l', false, 3}}},
+        {"ignores case", []typedKey{{'B', false, 2}, {'L', false, 3}}},
+        {"no match", []typedKey{{'c', false, 4}, {'b', false, 4}}},
+        {"timeout", []typedKey{{'c', false, 4}, {'b', true, 2}}},
+        {"timeout restarts cycle", []typedKey{{'b', false, 2}, {'l', false, 3}, {'b', true, 2}}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            env := newTestEnv(t, UNLIMITED_CAPACITY)
+
+            le := NewListElement(tcell.StyleDefault, NewStringListSource(items, tcell.StyleDefault),
+                NewListOptions())
+
+            eid, err := env.Register(le)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            // This will never error.
+            ectx, _ := env.GetElementContext(eid)
+
+            // Start on "apple".
+            err = le.selectItem(ectx, 0)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            for _, k := range tc.keys {
+                if k.late {
+                    le.typedAt = le.typedAt.Add(-2 * typeAheadTimeout)
+                }
+
+                err := le.typeAhead(ectx, tcell.NewEventKey(tcell.KeyRune, k.r, tcell.ModNone))
+                if err != nil {
+                    t.Fatal(err)
+                }
+
+                if le.selected != k.expected {
+                    t.Fatalf("after %q, expected %d selected, got %d", k.r, k.expected, le.selected)
+                }
+            }
+        })
+    }
+}
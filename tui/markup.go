@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Styled Text --------------------------------------
+
+// Styled text is a sequence of spans, each with its own style.
+//
+// Styled text can be written using markup. A tag in square brackets
+// changes the style of all text which follows it:
+//
+//      [fg:bg:attrs:url]
+//
+// Any field can be left empty (or left off) to keep its current value.
+// A field of "-" resets it to the base style's value. The tag "[-]" resets
+// everything back to the base style.
+//
+// Colors are tcell color names or hex values. (e.g. "red", "#ff8800")
+// Attributes are letters which are turned on:
+//      b = bold, d = dim, i = italic, u = underline,
+//      r = reverse, l = blink, s = strikethrough
+//
+// For example:
+//      "[red::b]error[-] details"
+//      "see [blue::u:https://example.com]the docs[-]"
+//
+// "[[" is a literal "[". Anything in brackets which is not a valid tag is
+// left as is.
+
+type Span struct {
+    Text string
+    Style tcell.Style
+}
+
+type StyledText []Span
+
+// Styled text which is just the given text in a single style.
+func PlainText(t string, s tcell.Style) StyledText {
+    if len(t) == 0 {
+        return StyledText{}
+    }
+
+    return StyledText{{Text: t, Style: s}}
+}
+
+// The text without any styling.
+func (st StyledText) String() string {
+    var sb strings.Builder
+    for _, span := range st {
+        sb.WriteString(span.Text)
+    }
+
+    return sb.String()
+}
+
+func (st StyledText) Len() int {
+    n := 0
+    for _, span := range st {
+        n += len(span.Text)
+    }
+
+    return n
+}
+
+// Parses markup into styled text. Styles are built on top of base.
+func ParseMarkup(markup string, base tcell.Style) StyledText {
+    st := make(StyledText, 0)
+    style := base
+
+    var sb strings.Builder
+
+    // Ends the current span.
+    flush := func () {
+        if sb.Len() > 0 {
+            st = append(st, Span{Text: sb.String(), Style: style})
+            sb.Reset()
+        }
+    }
+
+    for i := 0; i < len(markup); {
+        if markup[i] != '[' {
+            sb.WriteByte(markup[i])
+            i++
+            continue
+        }
+
+        if strings.HasPrefix(markup[i:], "[[") {
+            sb.WriteByte('[')
+            i += 2
+            continue
+        }
+
+        end := strings.IndexByte(markup[i:], ']')
+        if end == -1 {
+            sb.WriteString(markup[i:])
+            break
+        }
+
+        tag := markup[i+1 : i+end]
+
+        // Tags never contain brackets, so this bracket can't start one.
+        if strings.IndexByte(tag, '[') != -1 {
+            sb.WriteByte('[')
+            i++
+            continue
+        }
+
+        next, ok := applyTag(style, base, tag)
+        if !ok {
+            sb.WriteString(markup[i : i+end+1])
+        } else if next != style {
+            flush()
+            style = next
+        }
+
+        i += end + 1
+    }
+
+    flush()
+    return st
+}
+
+// Returns the style after the given tag (without brackets) is applied.
+// ok is false if the tag is invalid.
+func applyTag(style tcell.Style, base tcell.Style, tag string) (tcell.Style, bool) {
+    if tag == "-" {
+        return base, true
+    }
+
+    // The url may itself contain colons.
+    fields := strings.SplitN(tag, ":", 4)
+    if len(tag) == 0 {
+        return style, false
+    }
+
+    baseFg, baseBg, baseAttrs := base.Decompose()
+
+    for i, field := range fields {
+        if field == "" {
+            continue
+        }
+
+        switch i {
+        case 0, 1:
+            color := baseFg
+            if i == 1 {
+                color = baseBg
+            }
+
+            if field != "-" {
+                color = tcell.GetColor(field)
+                if color == tcell.ColorDefault && field != "default" {
+                    return style, false
+                }
+            }
+
+            if i == 0 {
+                style = style.Foreground(color)
+            } else {
+                style = style.Background(color)
+            }
+
+        case 2:
+            if field == "-" {
+                style = style.Attributes(baseAttrs)
+                continue
+            }
+
+            for _, ru := range field {
+                switch ru {
+                case 'b':
+                    style = style.Bold(true)
+                case 'd':
+                    style = style.Dim(true)
+                case 'i':
+                    style = style.Italic(true)
+                case 'u':
+                    style = style.Underline(true)
+                case 'r':
+                    style = style.Reverse(true)
+                case 'l':
+                    style = style.Blink(true)
+                case 's':
+                    style = style.StrikeThrough(true)
+                default:
+                    return style, false
+                }
+            }
+
+        case 3:
+            if field == "-" {
+                field = ""
+            }
+
+            style = style.Url(field)
+        }
+    }
+
+    return style, true
+}
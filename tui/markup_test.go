@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseMarkup(t *testing.T) {
+    base := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+
+    red := base.Foreground(tcell.ColorRed)
+    bold := base.Bold(true)
+    redBold := red.Bold(true)
+    onBlue := base.Background(tcell.ColorBlue)
+    link := base.Url("https://example.com/a:b")
+
+    cases := []struct {
+        name string
+        markup string
+        expected StyledText
+    }{
+        {"plain", "hello", StyledText{{"hello", base}}},
+        {"empty", "", StyledText{}},
+        {"fg", "a[red]b", StyledText{{"a", base}, {"b", red}}},
+        {"bg", "[:blue]a", StyledText{{"a", onBlue}}},
+        {"attrs", "[::b]a", StyledText{{"a", bold}}},
+        {"fields add up", "[red]a[::b]b", StyledText{{"a", red}, {"b", redBold}}},
+        {"reset", "[red::b]a[-]b", StyledText{{"a", redBold}, {"b", base}}},
+        {"reset field", "[red::b]a[-]b[red::b]c[::-]d",
+            StyledText{{"a", redBold}, {"b", base}, {"c", redBold}, {"d", red}}},
+        {"reset fg", "[red]a[-:]b", StyledText{{"a", red}, {"b", base}}},
+        {"hex color", "[#ff0000]a", StyledText{{"a", base.Foreground(tcell.NewHexColor(0xff0000))}}},
+        {"url with colons", "[:::https://example.com/a:b]a", StyledText{{"a", link}}},
+        {"same style merges", "a[red]b[red]c", StyledText{{"a", base}, {"bc", red}}},
+        {"tag at end", "a[red]", StyledText{{"a", base}}},
+
+        // Escapes.
+        {"escaped bracket", "[[red]", StyledText{{"[red]", base}}},
+        {"escaped twice", "a[[[[b", StyledText{{"a[[b", base}}},
+        {"escape before tag", "[[[red]a", StyledText{{"[", base}, {"a", red}}},
+
+        // Malformed markup is left as is.
+        {"unknown color", "[nocolor]a", StyledText{{"[nocolor]a", base}}},
+        {"unknown attr", "[::z]a", StyledText{{"[::z]a", base}}},
+        {"empty tag", "[]a", StyledText{{"[]a", base}}},
+        {"unclosed", "a[red", StyledText{{"a[red", base}}},
+        {"nested open", "[a[red]b", StyledText{{"[a", base}, {"b", red}}},
+        {"lone close", "a]b", StyledText{{"a]b", base}}},
+        {"text in brackets", "[not a tag] x", StyledText{{"[not a tag] x", base}}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            actual := ParseMarkup(tc.markup, base)
+            if !slices.Equal(actual, tc.expected) {
+                t.Errorf("expected %v, got %v", tc.expected, actual)
+            }
+        })
+    }
+}
+
+func TestStyledText(t *testing.T) {
+    st := StyledText{{"ab", tcell.StyleDefault}, {"日", tcell.StyleDefault}}
+
+    if st.String() != "ab日" {
+        t.Errorf("expected %q, got %q", "ab日", st.String())
+    }
+
+    // Len counts bytes.
+    if st.Len() != 5 {
+        t.Errorf("expected length 5, got %d", st.Len())
+    }
+
+    if len(PlainText("", tcell.StyleDefault)) != 0 {
+        t.Error("empty plain text has spans")
+    }
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+type MouseAction int
+
+const (
+    // A button was pressed over the element.
+    MOUSE_PRESS MouseAction = iota
+
+    // The buttons pressed over the element were released.
+    // This is sent to the element which received the press, even if the
+    // cursor has since left it.
+    MOUSE_RELEASE
+
+    // Sent after a release when the cursor is still over the element
+    // which received the press.
+    MOUSE_CLICK
+
+    // The cursor moved with no buttons pressed.
+    MOUSE_MOVE
+
+    // The cursor moved with buttons pressed.
+    // Like releases, drags are sent to the element which received the press.
+    MOUSE_DRAG
+
+    // The wheel was scrolled over the element.
+    MOUSE_WHEEL
+
+    // The cursor entered/left the element.
+    // These do not bubble.
+    MOUSE_ENTER
+    MOUSE_LEAVE
+)
+
+// Mouse events are created by the environment from tcell mouse events.
+// (See Environment.routeMouse)
+//
+// A mouse event is dispatched to its target, the deepest element under
+// the cursor. (See Dispatch) Every element along the way receives a copy 
+// with coordinates relative to its own origin.
+type MouseEvent struct {
+    at time.Time
+
+    action MouseAction
+
+    target ElementID
+
+    // Relative to the receiving element.
+    x, y int
+
+    buttons tcell.ButtonMask
+    mods tcell.ModMask
+}
+
+func (me MouseEvent) When() time.Time {
+    return me.at
+}
+
+func (me MouseEvent) Action() MouseAction {
+    return me.action
+}
+
+func (me MouseEvent) Target() ElementID {
+    return me.target
+}
+
+// Position relative to the receiving element's origin.
+// This can lie outside of the element during drags.
+func (me MouseEvent) Position() (int, int) {
+    return me.x, me.y
+}
+
+func (me MouseEvent) Buttons() tcell.ButtonMask {
+    return me.buttons
+}
+
+func (me MouseEvent) Modifiers() tcell.ModMask {
+    return me.mods
+}
+
+const wheelMask = tcell.WheelUp | tcell.WheelDown | tcell.WheelLeft | tcell.WheelRight
+
+// Converts screen coordinates into the coordinate space of the given element.
+// (Relative to the element's origin)
+func (env *Environment) toLocal(eid ElementID, x, y int) (int, int) {
+    path := make([]ElementID, 0)
+    for ; eid != NULL_EID; eid = env.entry(eid).ectx.parentID {
+        path = append(path, eid)
+    }
+
+    // Apply viewports from the top of the tree down.
+    for i := len(path) - 1; i >= 0; i-- {
+        vp := env.entry(path[i]).ectx.viewport
+        if vp != nil {
+            x = x - vp.x + vp.xOff
+            y = y - vp.y + vp.yOff
+        }
+    }
+
+    ectx := env.entry(path[0]).ectx
+    return x - ectx.c, y - ectx.r
+}
+
+// Returns the deepest element under the given point, or NULL_EID.
+// Children are searched last to first, since later children are
+// drawn on top.
+func (env *Environment) hitTest(eid ElementID, x, y int) ElementID {
+    ectx := env.entry(eid).ectx
+
+    if ectx.hidden {
+        return NULL_EID
+    }
+
+    if vp := ectx.viewport; vp != nil {
+        if x < vp.x || y < vp.y || vp.x + vp.width <= x || vp.y + vp.height <= y {
+            return NULL_EID
+        }
+
+        x = x - vp.x + vp.xOff
+        y = y - vp.y + vp.yOff
+    }
+
+    if x < ectx.c || y < ectx.r || ectx.c + ectx.cols <= x || ectx.r + ectx.rows <= y {
+        return NULL_EID
+    }
+
+    for i := len(ectx.children) - 1; i >= 0; i-- {
+        hit := env.hitTest(ectx.children[i].id, x, y)
+        if hit != NULL_EID {
+            return hit
+        }
+    }
+
+    return eid
+}
+
+// Returns the element under the given screen position, or NULL_EID.
+// Layers are searched from the top down, nothing below the topmost
+// modal layer can be hit. (See layer.go)
+func (env *Environment) ElementAt(x, y int) ElementID {
+    roots := env.activeRoots()
+
+    for i := len(roots) - 1; i >= 0; i-- {
+        hit := env.hitTest(roots[i], x, y)
+        if hit != NULL_EID {
+            return hit
+        }
+    }
+
+    return NULL_EID
+}
+
+// Dispatches a mouse event to the given target. (See Dispatch)
+// If bubble is false, only the target receives the event.
+func (env *Environment) deliverMouse(target ElementID, action MouseAction, 
+    ev *tcell.EventMouse, bubble bool) error {
+    x, y := ev.Position()
+
+    _, err := env.dispatch(target, func (eid ElementID) tcell.Event {
+        lx, ly := env.toLocal(eid, x, y)
+
+        return &MouseEvent{
+            at: ev.When(),
+            action: action,
+            target: target,
+            x: lx,
+            y: ly,
+            buttons: ev.Buttons(),
+            mods: ev.Modifiers(),
+        }
+    }, bubble)
+
+    return err
+}
+
+// Returns true if eid names a registered element.
+// Handlers can deregister elements while a mouse event is being routed,
+// so IDs are checked again after every delivery.
+func (env *Environment) isLive(eid ElementID) bool {
+    _, err := env.getEnvEntry(eid)
+    return err == nil
+}
+
+// Turns a tcell mouse event into mouse events for the elements involved.
+//
+// NOTE: After a press, the pressed element captures the mouse until all
+// buttons are released. This is how drags are delivered.
+func (env *Environment) routeMouse(ev *tcell.EventMouse) error {
+    x, y := ev.Position()
+    target := env.ElementAt(x, y)
+
+    // Hover tracking.
+    if target != env.hoverID {
+        if env.isLive(env.hoverID) {
+            err := env.deliverMouse(env.hoverID, MOUSE_LEAVE, ev, false)
+            if err != nil {
+                return err
+            }
+        }
+
+        if !env.isLive(target) {
+            target = NULL_EID
+        }
+
+        env.hoverID = target
+
+        if target != NULL_EID {
+            err := env.deliverMouse(target, MOUSE_ENTER, ev, false)
+            if err != nil {
+                return err
+            }
+        }
+    }
+
+    buttons := ev.Buttons()
+    wheel := buttons & wheelMask
+    pressed := buttons &^ wheelMask
+
+    if wheel != 0 && env.isLive(target) {
+        err := env.deliverMouse(target, MOUSE_WHEEL, ev, true)
+        if err != nil {
+            return err
+        }
+    }
+
+    if !env.isLive(target) {
+        target = NULL_EID
+    }
+
+    if env.captureID != NULL_EID {
+        captureID := env.captureID
+
+        if pressed != tcell.ButtonNone {
+            if !env.isLive(captureID) {
+                env.captureID = NULL_EID
+                return nil
+            }
+
+            return env.deliverMouse(captureID, MOUSE_DRAG, ev, true)
+        }
+
+        env.captureID = NULL_EID
+
+        if !env.isLive(captureID) {
+            return nil
+        }
+
+        err := env.deliverMouse(captureID, MOUSE_RELEASE, ev, true)
+        if err != nil {
+            return err
+        }
+
+        // The release handler may have removed the element.
+        if target == captureID && env.isLive(captureID) {
+            return env.deliverMouse(captureID, MOUSE_CLICK, ev, true)
+        }
+
+        return nil
+    }
+
+    if target == NULL_EID {
+        return nil
+    }
+
+    if pressed != tcell.ButtonNone {
+        env.captureID = target
+        return env.deliverMouse(target, MOUSE_PRESS, ev, true)
+    }
+
+    if wheel == 0 {
+        return env.deliverMouse(target, MOUSE_MOVE, ev, true)
+    }
+
+    return nil
+}
@@ -0,0 +1,237 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Probes a and b side by side, each 10 columns wide.
+type mouseFixture struct {
+    h *tuitest.Harness
+    log []string
+    a, b *probe
+}
+
+func newMouseFixture(t *testing.T) *mouseFixture {
+    mf := &mouseFixture{}
+    mf.a = newProbe("a", &mf.log, false)
+    mf.b = newProbe("b", &mf.log, false)
+
+    mf.h = tuitest.New(t, 2, 20, tui.DividedElementF(true, false, plain,
+        tui.FlexDivision(1, mf.a.F()),
+        tui.FlexDivision(1, mf.b.F())))
+
+    return mf
+}
+
+func (mf *mouseFixture) move(x, y int) {
+    mf.h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+func (mf *mouseFixture) press(x, y int) {
+    mf.h.Mouse(x, y, tcell.Button1, tcell.ModNone)
+}
+
+func TestMouseRouting(t *testing.T) {
+    cases := []struct {
+        name string
+        events func (mf *mouseFixture)
+        expected []string
+    }{
+        {"click", func (mf *mouseFixture) {
+            mf.h.Click(2, 0)
+        }, []string{"a:enter", "a:press", "a:release", "a:click"}},
+        {"hover", func (mf *mouseFixture) {
+            mf.move(2, 0)
+            mf.move(3, 1)
+            mf.move(12, 0)
+        }, []string{"a:enter", "a:move", "a:move", "a:leave", "b:enter", "b:move"}},
+        {"wheel", func (mf *mouseFixture) {
+            mf.h.Mouse(12, 0, tcell.WheelDown, tcell.ModNone)
+        }, []string{"b:enter", "b:wheel"}},
+
+        // The pressed element receives the drag and release, but no click.
+        {"drag", func (mf *mouseFixture) {
+            mf.press(2, 0)
+            mf.press(12, 0)
+            mf.move(12, 0)
+        }, []string{"a:enter", "a:press", "a:leave", "b:enter", "a:drag", "a:release"}},
+        {"drag back", func (mf *mouseFixture) {
+            mf.press(2, 0)
+            mf.press(12, 0)
+            mf.press(3, 0)
+            mf.move(3, 0)
+        }, []string{"a:enter", "a:press", "a:leave", "b:enter", "a:drag",
+            "b:leave", "a:enter", "a:drag", "a:release", "a:click"}},
+        {"leave the screen", func (mf *mouseFixture) {
+            mf.move(2, 0)
+            mf.move(30, 0)
+        }, []string{"a:enter", "a:move", "a:leave"}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            mf := newMouseFixture(t)
+            tc.events(mf)
+            expectLog(t, mf.log, tc.expected...)
+        })
+    }
+}
+
+// Positions are relative to the receiving element, even outside of it.
+func TestMousePosition(t *testing.T) {
+    mf := newMouseFixture(t)
+
+    var x, y int
+    mf.a.onEvent = func (ectx *tui.ElementContext, ev tcell.Event) error {
+        if mev, ok := ev.(*tui.MouseEvent); ok {
+            x, y = mev.Position()
+        }
+
+        return nil
+    }
+
+    mf.b.onEvent = mf.a.onEvent
+
+    mf.move(13, 1)
+    if x != 3 || y != 1 {
+        t.Errorf("expected (3, 1), got (%d, %d)", x, y)
+    }
+
+    mf.press(2, 0)
+    mf.press(15, 1)
+    if x != 15 || y != 1 {
+        t.Errorf("expected (15, 1) while dragging, got (%d, %d)", x, y)
+    }
+}
+
+func TestMouseThroughViewport(t *testing.T) {
+    var log []string
+    rows := make([]*probe, 4)
+    divs := make([]tui.Division, 4)
+
+    for i := range rows {
+        rows[i] = newProbe(string(rune('0' + i)), &log, false)
+        divs[i] = tui.FixedDivision(1, rows[i].F())
+    }
+
+    h := tuitest.New(t, 2, 10, tui.ScrollElementF(true, false, false, plain,
+        tui.DividedElementF(false, false, plain, divs...)))
+
+    // Each wheel step scrolls one row. Hovering follows the row under the
+    // cursor as of each event.
+    h.Mouse(0, 0, tcell.WheelDown, tcell.ModNone)
+    h.Mouse(0, 0, tcell.WheelDown, tcell.ModNone)
+
+    log = log[:0]
+    h.Click(0, 0)
+    h.Click(0, 1)
+
+    expectLog(t, log, "1:leave", "2:enter", "2:press", "2:release", "2:click",
+        "2:leave", "3:enter", "3:press", "3:release", "3:click")
+}
+
+func TestMouseLayers(t *testing.T) {
+    cases := []struct {
+        name string
+        modal bool
+        expected []string
+    }{
+        {"over", false, []string{"a:enter", "a:press", "a:release", "a:click",
+            "a:leave", "l:enter", "l:press", "l:release", "l:click"}},
+        {"modal", true, []string{"l:enter", "l:press", "l:release", "l:click"}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            mf := newMouseFixture(t)
+            env := mf.h.Env()
+
+            // The layer covers b.
+            l := newProbe("l", &mf.log, false)
+            lid, err := env.CreateAndRegister(l.F())
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            spec := tui.NewLayerSpec(tui.ElementAnchor(mf.b.id, tui.PLACE_FILL))
+            if tc.modal {
+                spec = spec.Modal()
+            }
+
+            err = env.PushLayer(lid, spec)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            mf.h.Flush()
+
+            mf.h.Click(2, 0)
+            mf.h.Click(12, 0)
+
+            expectLog(t, mf.log, tc.expected...)
+        })
+    }
+}
+
+// A handler can remove its own element part way through routing.
+func TestMouseRemovedByHandler(t *testing.T) {
+    cases := []struct {
+        name string
+        action tui.MouseAction
+        events func (mf *mouseFixture)
+        expected []string
+    }{
+        {"release", tui.MOUSE_RELEASE, func (mf *mouseFixture) {
+            mf.h.Click(2, 0)
+        }, []string{"a:enter", "a:press", "a:release", "b:enter"}},
+        {"press", tui.MOUSE_PRESS, func (mf *mouseFixture) {
+            mf.h.Click(2, 0)
+        }, []string{"a:enter", "a:press", "b:enter"}},
+        {"enter", tui.MOUSE_ENTER, func (mf *mouseFixture) {
+            mf.h.Click(2, 0)
+        }, []string{"a:enter", "b:enter"}},
+        {"leave", tui.MOUSE_LEAVE, func (mf *mouseFixture) {
+            mf.move(2, 0)
+            mf.move(12, 0)
+        }, []string{"a:enter", "a:move", "a:leave", "b:enter", "b:move"}},
+        {"wheel", tui.MOUSE_WHEEL, func (mf *mouseFixture) {
+            mf.h.Mouse(2, 0, tcell.WheelDown | tcell.Button1, tcell.ModNone)
+            mf.move(2, 0)
+        }, []string{"a:enter", "a:wheel", "b:enter"}},
+        {"drag", tui.MOUSE_DRAG, func (mf *mouseFixture) {
+            mf.press(2, 0)
+            mf.press(3, 0)
+            mf.press(4, 0)
+            mf.move(4, 0)
+        }, []string{"a:enter", "a:press", "a:drag", "b:enter"}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            mf := newMouseFixture(t)
+            env := mf.h.Env()
+
+            mf.a.onEvent = func (ectx *tui.ElementContext, ev tcell.Event) error {
+                if mev, ok := ev.(*tui.MouseEvent); ok && mev.Action() == tc.action {
+                    return ectx.DetachAndDeregister()
+                }
+
+                return nil
+            }
+
+            tc.events(mf)
+
+            if _, err := env.GetElementContext(mf.a.id); err == nil {
+                t.Fatalf("expected a to be deregistered")
+            }
+
+            // Routing carries on as normal.
+            mf.h.Click(12, 1)
+            expectLog(t, mf.log, append(tc.expected, "b:press", "b:release", "b:click")...)
+        })
+    }
+}
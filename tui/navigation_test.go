@@ -0,0 +1,63 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Shared by the list, table and tree tests.
+
+// Returns a 4x20 harness showing the element made by f, focused by a
+// click at (x, y).
+func newFocusedHarness(t *testing.T, f tui.ElementFactory, x, y int) *tuitest.Harness {
+    t.Helper()
+
+    h := tuitest.New(t, 4, 20, f)
+
+    h.Click(x, y)
+    if h.Env().FocusedID() == tui.NULL_EID {
+        t.Fatal("the element was not focused")
+    }
+
+    return h
+}
+
+// Every navigation key, along with typing, clicks and the wheel.
+func navigate(h *tuitest.Harness) {
+    keys := []tcell.Key{
+        tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn,
+        tcell.KeyHome, tcell.KeyEnd, tcell.KeyLeft, tcell.KeyRight,
+        tcell.KeyEnter,
+    }
+
+    for _, k := range keys {
+        h.Key(k, tcell.ModNone)
+    }
+
+    h.Type("a 12")
+    h.Click(0, 0)
+    h.Click(0, 2)
+    h.Mouse(0, 2, tcell.WheelDown, tcell.ModNone)
+    h.Mouse(0, 2, tcell.WheelUp, tcell.ModNone)
+}
+
+// Navigates an element with nothing in it. Nothing should be selected
+// and none of the element's callbacks, which count into calls, should
+// be called.
+func testEmptyNavigation(t *testing.T, f tui.ElementFactory, selected func () bool, calls *int) {
+    t.Helper()
+
+    h := newFocusedHarness(t, f, 0, 0)
+    navigate(h)
+
+    if selected() {
+        t.Error("expected no selection")
+    }
+
+    if *calls != 0 {
+        t.Errorf("expected no callbacks, got %d", *calls)
+    }
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Post and PostEvent are the only Environment calls which are safe to make
+// from any goroutine. Posted work is queued, then performed by the
+// environment's own goroutine during Run. (Between polling tcell events
+// and sending update ticks)
+//
+// This is how results of background work (network calls, file watchers, etc.)
+// should be applied to elements.
+
+// Post queues fn to be called on the environment's goroutine.
+func (env *Environment) Post(fn func (*Environment)) {
+    env.postMu.Lock()
+    defer env.postMu.Unlock()
+
+    env.posted = append(env.posted, func (env *Environment) error {
+        fn(env)
+        return nil
+    })
+}
+
+// PostEvent queues ev to be dispatched to the given element on the
+// environment's goroutine. (See Dispatch)
+//
+// NOTE: If the element is deregistered before the event is processed,
+// the event is dropped.
+func (env *Environment) PostEvent(eid ElementID, ev tcell.Event) {
+    env.postMu.Lock()
+    defer env.postMu.Unlock()
+
+    env.posted = append(env.posted, func (env *Environment) error {
+        if _, err := env.getEnvEntry(eid); err != nil {
+            return nil
+        }
+
+        _, err := env.Dispatch(eid, ev)
+        return err
+    })
+}
+
+// ProcessPosted performs all work posted so far, in the order it was posted.
+// Work posted while processing is left for the next call.
+func (env *Environment) ProcessPosted() error {
+    env.postMu.Lock()
+    posted := env.posted
+    env.posted = nil
+    env.postMu.Unlock()
+
+    for i, fn := range posted {
+        err := fn(env)
+        if err != nil {
+            // Work which was not performed is put back at the front
+            // of the queue.
+            env.postMu.Lock()
+            env.posted = append(posted[i+1:], env.posted...)
+            env.postMu.Unlock()
+
+            return fmt.Errorf("ProcessPosted: %w", err)
+        }
+    }
+
+    return nil
+}
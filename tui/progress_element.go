@@ -0,0 +1,400 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Bars --------------------------------------
+
+// Blocks filling 1/8 to 8/8 of a cell from the left.
+var eighthBlocks = []rune{'▏', '▎', '▍', '▌', '▋', '▊', '▉', '█'}
+
+// Draws a bar of the given width on row r, filled to frac (0 to 1).
+// The filled part takes style fs, the rest takes style es.
+//
+// Each cell is split into eighths, so the bar can end partway through
+// a cell.
+func drawBar(cv *Canvas, r, c int, width int, frac float64, fs tcell.Style, es tcell.Style) {
+    frac = math.Max(math.Min(frac, 1), 0)
+    eighths := int(math.Round(frac * float64(width * 8)))
+
+    for i := 0; i < width; i++ {
+        filled := eighths - i * 8
+
+        switch {
+        case filled >= 8:
+            cv.SetCellAt(r, c + i, eighthBlocks[7], fs)
+        case filled > 0:
+            cv.SetCellAt(r, c + i, eighthBlocks[filled - 1], fs)
+        default:
+            cv.SetCellAt(r, c + i, ' ', es)
+        }
+    }
+}
+
+// The width bars would like by default.
+const prefBarWidth = 20
+
+// -------------------------------------- Progress Element --------------------------------------
+
+// A progress element is a bar filled to show how much of a task is done,
+// followed by the percentage done:
+//
+//      ██████████▌          52%
+type ProgressElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    barStyle tcell.Style
+
+    // From 0 to 1.
+    progress float64
+}
+
+// The bar is drawn in style bs, everything else in style s.
+func NewProgressElement(s tcell.Style, bs tcell.Style) *ProgressElement {
+    return &ProgressElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        barStyle: bs,
+        progress: 0,
+    }
+}
+
+func ProgressElementF(s tcell.Style, bs tcell.Style) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewProgressElement(s, bs))
+    }
+}
+
+func (pe *ProgressElement) GetProgress() float64 {
+    return pe.progress
+}
+
+// p is clamped between 0 and 1.
+func (pe *ProgressElement) SetProgress(ectx *ElementContext, p float64) {
+    p = math.Max(math.Min(p, 1), 0)
+    if p == pe.progress {
+        return
+    }
+
+    pe.progress = p
+    ectx.SetDrawFlag()
+}
+
+// The percentage label is always 5 columns wide. (e.g. "  52%")
+const percentWidth = 5
+
+// A progress element is a single row.
+func (pe *ProgressElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    return SizeHint{
+        PrefRows: 1,
+        PrefCols: prefBarWidth + percentWidth,
+        MinRows: 1,
+        MinCols: percentWidth,
+        MaxRows: 1,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (pe *ProgressElement) Draw(cv *Canvas) {
+    cv.Fill(' ', pe.style)
+
+    width := max(cv.Cols() - percentWidth, 0)
+    drawBar(cv, 0, 0, width, pe.progress, pe.barStyle, pe.style)
+
+    label := fmt.Sprintf("%4d%%", int(math.Round(pe.progress * 100)))
+    cv.PrintStyled(0, width, label, pe.style)
+}
+
+// -------------------------------------- Gauge Element --------------------------------------
+
+// A gauge element shows a value within a range as a titled bar:
+//
+//      CPU ████████▊       63.5
+//
+// The bar's style depends on which level the value has reached.
+// (For example, turning red near the top of the range)
+type GaugeElement struct {
+    *DefaultElement
+
+    style tcell.Style
+
+    title string
+    lo, hi float64
+    levels []GaugeLevel
+
+    value float64
+}
+
+// Once a gauge's value is at least at, its bar takes style s.
+type GaugeLevel struct {
+    at float64
+    s tcell.Style
+}
+
+func NewGaugeLevel(at float64, s tcell.Style) GaugeLevel {
+    return GaugeLevel{
+        at: at,
+        s: s,
+    }
+}
+
+// The gauge's bar goes from lo to hi. Levels should be given in order
+// from lowest to highest. If the value is below every level, the bar takes
+// style s.
+func NewGaugeElement(s tcell.Style, title string, lo, hi float64, levels ...GaugeLevel) *GaugeElement {
+    return &GaugeElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        title: title,
+        lo: lo,
+        hi: hi,
+        levels: levels,
+        value: lo,
+    }
+}
+
+func GaugeElementF(s tcell.Style, title string, lo, hi float64, levels ...GaugeLevel) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewGaugeElement(s, title, lo, hi, levels...))
+    }
+}
+
+func (ge *GaugeElement) GetValue() float64 {
+    return ge.value
+}
+
+// The value is shown as given, but the bar never leaves the gauge's range.
+func (ge *GaugeElement) SetValue(ectx *ElementContext, v float64) {
+    if v == ge.value {
+        return
+    }
+
+    ge.value = v
+    ectx.SetDrawFlag()
+}
+
+func (ge *GaugeElement) barStyle() tcell.Style {
+    style := ge.style
+    for _, l := range ge.levels {
+        if ge.value >= l.at {
+            style = l.s
+        }
+    }
+
+    return style
+}
+
+func (ge *GaugeElement) valueLabel() string {
+    return fmt.Sprintf("%.1f", ge.value)
+}
+
+func (ge *GaugeElement) fraction() float64 {
+    if ge.hi <= ge.lo {
+        return 0
+    }
+
+    return (ge.value - ge.lo) / (ge.hi - ge.lo)
+}
+
+// A gauge element is a single row.
+func (ge *GaugeElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    title := toLine(PlainText(ge.title, ge.style), 1).width
+
+    // Room for the widest label the range should produce.
+    label := max(len(fmt.Sprintf("%.1f", ge.lo)), len(fmt.Sprintf("%.1f", ge.hi)))
+
+    return SizeHint{
+        PrefRows: 1,
+        PrefCols: title + 1 + prefBarWidth + 1 + label,
+        MinRows: 1,
+        MinCols: 0,
+        MaxRows: 1,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (ge *GaugeElement) Draw(cv *Canvas) {
+    cv.Fill(' ', ge.style)
+
+    c := 0
+    if ge.title != "" {
+        c = cv.PrintStyled(0, 0, ge.title, ge.style) + 1
+    }
+
+    label := ge.valueLabel()
+    width := max(cv.Cols() - c - len(label) - 1, 0)
+
+    drawBar(cv, 0, c, width, ge.fraction(), ge.barStyle(), ge.style)
+    cv.PrintStyled(0, c + width + 1, label, ge.style)
+}
+
+// -------------------------------------- Spinner Element --------------------------------------
+
+// A spinner element shows that something is happening. While spinning, it
+// moves to its next frame once per interval. Frames are only advanced on
+// update ticks, so an interval shorter than the update duration behaves
+// like one tick. (See UpdateTickEvent)
+//
+// The spinner is followed by an optional label:
+//
+//      ⠹ Connecting...
+
+// Frame sets for spinners. Every frame of a set should be the same width.
+var (
+    SPINNER_LINE = []string{"-", "\\", "|", "/"}
+    SPINNER_DOTS = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+    SPINNER_ARC = []string{"◜", "◠", "◝", "◞", "◡", "◟"}
+    SPINNER_QUADRANT = []string{"▖", "▘", "▝", "▗"}
+    SPINNER_BOUNCE = []string{"▁", "▃", "▄", "▅", "▆", "▇", "█", "▇", "▆", "▅", "▄", "▃"}
+)
+
+const DEFAULT_SPINNER_INTERVAL = 100 * time.Millisecond
+
+type SpinnerElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    frames []string
+    label string
+
+    frame int
+    spinning bool
+
+    interval time.Duration
+
+    // When the frame last changed. (On the environment's clock)
+    // -1 until the first tick.
+    stepped time.Duration
+}
+
+// A new spinner is spinning, with interval DEFAULT_SPINNER_INTERVAL.
+func NewSpinnerElement(s tcell.Style, frames []string, label string) *SpinnerElement {
+    return &SpinnerElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        frames: frames,
+        label: label,
+        frame: 0,
+        spinning: true,
+        interval: DEFAULT_SPINNER_INTERVAL,
+        stepped: -1,
+    }
+}
+
+func SpinnerElementF(s tcell.Style, frames []string, label string) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewSpinnerElement(s, frames, label))
+    }
+}
+
+// While stopped, no frame is shown, just the label.
+func (se *SpinnerElement) SetSpinning(ectx *ElementContext, spinning bool) {
+    if spinning == se.spinning {
+        return
+    }
+
+    se.spinning = spinning
+    se.stepped = -1
+
+    ectx.SetDrawFlag()
+}
+
+func (se *SpinnerElement) IsSpinning() bool {
+    return se.spinning
+}
+
+func (se *SpinnerElement) GetInterval() time.Duration {
+    return se.interval
+}
+
+// The time between frames.
+// This can be set before the spinner is registered.
+func (se *SpinnerElement) SetInterval(d time.Duration) {
+    se.interval = d
+}
+
+func (se *SpinnerElement) SetFrames(ectx *ElementContext, frames []string) {
+    se.frames = frames
+    se.frame = 0
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+}
+
+func (se *SpinnerElement) GetLabel() string {
+    return se.label
+}
+
+func (se *SpinnerElement) SetLabel(ectx *ElementContext, label string) {
+    se.label = label
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+}
+
+// The width of the widest frame.
+func (se *SpinnerElement) frameWidth() int {
+    w := 0
+    for _, f := range se.frames {
+        w = max(w, toLine(PlainText(f, se.style), 1).width)
+    }
+
+    return w
+}
+
+// A spinner element is a single row.
+func (se *SpinnerElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    cols := se.frameWidth()
+    if se.label != "" {
+        cols += 1 + toLine(PlainText(se.label, se.style), 1).width
+    }
+
+    return SizeHint{
+        PrefRows: 1,
+        PrefCols: cols,
+        MinRows: 1,
+        MinCols: 0,
+        MaxRows: 1,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (se *SpinnerElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    if _, ok := ev.(*UpdateTickEvent); !ok || !se.spinning || len(se.frames) == 0 {
+        return nil
+    }
+
+    // The first tick only starts the interval.
+    now := ectx.Clock()
+    if se.stepped == -1 {
+        se.stepped = now
+        return nil
+    }
+
+    if now - se.stepped >= se.interval {
+        se.frame = (se.frame + 1) % len(se.frames)
+        se.stepped = now
+
+        ectx.SetDrawFlag()
+    }
+
+    return nil
+}
+
+func (se *SpinnerElement) Draw(cv *Canvas) {
+    cv.Fill(' ', se.style)
+
+    if se.spinning && len(se.frames) > 0 {
+        cv.PrintStyled(0, 0, se.frames[se.frame], se.style)
+    }
+
+    if se.label != "" {
+        cv.PrintStyled(0, se.frameWidth() + 1, se.label, se.style)
+    }
+}
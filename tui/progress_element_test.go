@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Returns a single row screen of the given width.
+func newRowScreen(t *testing.T, cols int) tcell.SimulationScreen {
+    s := tcell.NewSimulationScreen("UTF-8")
+    err := s.Init()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    t.Cleanup(s.Fini)
+    s.SetSize(cols, 1)
+
+    return s
+}
+
+// The characters on the first row of the screen.
+func rowString(s tcell.SimulationScreen) string {
+    cols, _ := s.Size()
+
+    var sb strings.Builder
+    for x := 0; x < cols; x++ {
+        ru, _, _, _ := s.GetContent(x, 0)
+        sb.WriteRune(ru)
+    }
+
+    return sb.String()
+}
+
+func TestDrawBar(t *testing.T) {
+    fs := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+    es := tcell.StyleDefault
+
+    cases := []struct {
+        name string
+        frac float64
+        expected string
+    }{
+        {"empty", 0, "    "},
+        {"full", 1, "████"},
+        {"half", 0.5, "██  "},
+        {"one eighth", 1.0 / 32, "▏   "},
+        {"seven eighths", 7.0 / 32, "▉   "},
+        {"partial cell", 0.3, "█▎  "},
+        {"rounds down", 0.01, "    "},
+        {"rounds up", 0.99, "████"},
+        {"below range", -1, "    "},
+        {"above range", 2, "████"},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            s := newRowScreen(t, 4)
+            drawBar(NewCanvas(s, 0, 0, 1, 4), 0, 0, 4, tc.frac, fs, es)
+
+            if actual := rowString(s); actual != tc.expected {
+                t.Fatalf("expected %q, got %q", tc.expected, actual)
+            }
+
+            for x, ru := range []rune(tc.expected) {
+                expected := fs
+                if ru == ' ' {
+                    expected = es
+                }
+
+                if _, _, style, _ := s.GetContent(x, 0); style != expected {
+                    t.Errorf("cell %d has the wrong style", x)
+                }
+            }
+        })
+    }
+}
+
+func TestProgressLabel(t *testing.T) {
+    cases := []struct {
+        progress float64
+        expected string
+    }{
+        {0, "   0%"},
+        {0.519, "  52%"},
+        {0.006, "   1%"},
+        {0.999, " 100%"},
+        {1, " 100%"},
+    }
+
+    for _, tc := range cases {
+        s := newRowScreen(t, 10)
+
+        pe := NewProgressElement(tcell.StyleDefault, tcell.StyleDefault)
+        pe.progress = tc.progress
+        pe.Draw(NewCanvas(s, 0, 0, 1, 10))
+
+        row := rowString(s)
+        if actual := row[len(row) - percentWidth:]; actual != tc.expected {
+            t.Errorf("%v: expected %q, got %q", tc.progress, tc.expected, actual)
+        }
+    }
+}
+
+func TestSpinnerInterval(t *testing.T) {
+    s := newRowScreen(t, 10)
+
+    // Ticks are 10ms apart.
+    env := NewEnvironment(s, UNLIMITED_CAPACITY, 10 * time.Millisecond)
+
+    se := NewSpinnerElement(tcell.StyleDefault, SPINNER_LINE, "")
+    se.SetInterval(30 * time.Millisecond)
+
+    eid, err := env.Register(se)
+    if err == nil {
+        err = env.MakeRoot(eid)
+    }
+
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    frames := []int{}
+    for i := 0; i < 10; i++ {
+        err := env.Tick()
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        frames = append(frames, se.frame)
+    }
+
+    expected := []int{0, 0, 0, 1, 1, 1, 2, 2, 2, 3}
+    for i := range expected {
+        if frames[i] != expected[i] {
+            t.Fatalf("expected frames %v, got %v", expected, frames)
+        }
+    }
+
+    // Stopped spinners stay put.
+    se.spinning = false
+    err = env.Tick()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if se.frame != 3 {
+        t.Errorf("expected frame 3, got %d", se.frame)
+    }
+}
@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Events dispatched through the environment travel like events in the DOM.
+//
+// 1) Capture: From the top of the target's tree down to the target's parent.
+//    Only elements which implement CaptureHandler take part in this phase.
+//
+// 2) Target: The target's HandleEvent is called.
+//
+// 3) Bubble: From the target's parent back up to the top of the tree.
+//    Each element's HandleEvent is called.
+//
+// Any handler can stop propagation, or mark the event as handled.
+// (See ElementContext.StopPropagation and ElementContext.MarkHandled)
+type EventPhase int
+
+const (
+    // The event was forwarded straight to the element. (See ForwardEvent)
+    PHASE_NONE EventPhase = iota
+
+    PHASE_CAPTURE
+    PHASE_TARGET
+    PHASE_BUBBLE
+)
+
+// Elements which want to see events before their descendants do can
+// implement this interface. 
+type CaptureHandler interface {
+    CaptureEvent(ectx *ElementContext, ev tcell.Event) error
+}
+
+type dispatchState struct {
+    target ElementID
+    phase EventPhase
+
+    stopped bool
+    handled bool
+}
+
+// Routed events are delivered by the environment to exactly the elements
+// which should receive them. Container elements should not forward these
+// to their children.
+func IsRoutedEvent(ev tcell.Event) bool {
+    switch ev.(type) {
+    case *MouseEvent, *FocusEvent, *BlurEvent, *tcell.EventKey, *tcell.EventPaste:
+        return true
+    }
+
+    return false
+}
+
+func (env *Environment) currDispatch() *dispatchState {
+    if len(env.dispatches) == 0 {
+        return nil
+    }
+
+    return env.dispatches[len(env.dispatches) - 1]
+}
+
+func (env *Environment) pushDispatch(ds *dispatchState) {
+    env.dispatches = append(env.dispatches, ds)
+}
+
+func (env *Environment) popDispatch() {
+    env.dispatches = env.dispatches[:len(env.dispatches) - 1]
+}
+
+// Dispatch sends an event to the given target using capture, target and 
+// bubble phases. Returns whether any handler marked the event as handled.
+func (env *Environment) Dispatch(target ElementID, ev tcell.Event) (bool, error) {
+    _, err := env.getEnvEntry(target)
+    if err != nil {
+        return false, fmt.Errorf("Dispatch: %w", err)
+    }
+
+    handled, err := env.dispatch(target, func (ElementID) tcell.Event {
+        return ev
+    }, true)
+
+    if err != nil {
+        return false, fmt.Errorf("Dispatch: %w", err)
+    }
+
+    return handled, nil
+}
+
+// Returns the entry of path[i], or nil if a handler has since
+// deregistered or detached an element between path[i] and the target.
+// (Dispatch stops when this happens)
+func (env *Environment) pathEntry(path []ElementID, i int) *EnvEntry {
+    for j := 0; j < i; j++ {
+        ee, err := env.getEnvEntry(path[j])
+        if err != nil || ee.ectx.parentID != path[j + 1] {
+            return nil
+        }
+    }
+
+    ee, err := env.getEnvEntry(path[i])
+    if err != nil {
+        return nil
+    }
+
+    return ee
+}
+
+// Dispatch helper.
+// mk creates the event each element will receive. (This way events can
+// be tailored per element, e.g. mouse coordinates)
+// If bubble is false, the bubble phase is skipped.
+func (env *Environment) dispatch(target ElementID, mk func (ElementID) tcell.Event, 
+    bubble bool) (bool, error) {
+
+    // path[0] is the target, path[len(path)-1] is the top of its tree.
+    path := make([]ElementID, 0)
+    for eid := target; eid != NULL_EID; {
+        ee, err := env.getEnvEntry(eid)
+        if err != nil {
+            return false, err
+        }
+
+        path = append(path, eid)
+        eid = ee.ectx.parentID
+    }
+
+    ds := &dispatchState{
+        target: target,
+        phase: PHASE_CAPTURE,
+        stopped: false,
+        handled: false,
+    }
+
+    env.pushDispatch(ds)
+    defer env.popDispatch()
+
+    for i := len(path) - 1; i > 0 && !ds.stopped; i-- {
+        ee := env.pathEntry(path, i)
+        if ee == nil {
+            return ds.handled, nil
+        }
+
+        ch, ok := ee.e.(CaptureHandler)
+        if !ok {
+            continue
+        }
+
+        err := ch.CaptureEvent(ee.ectx, mk(path[i]))
+        if err != nil {
+            return ds.handled, err
+        }
+    }
+
+    if ds.stopped {
+        return ds.handled, nil
+    }
+
+    ds.phase = PHASE_TARGET
+
+    ee := env.pathEntry(path, 0)
+    if ee == nil {
+        return ds.handled, nil
+    }
+
+    err := ee.e.HandleEvent(ee.ectx, mk(target))
+    if err != nil {
+        return ds.handled, err
+    }
+
+    if !bubble {
+        return ds.handled, nil
+    }
+
+    ds.phase = PHASE_BUBBLE
+
+    for i := 1; i < len(path) && !ds.stopped; i++ {
+        ee := env.pathEntry(path, i)
+        if ee == nil {
+            return ds.handled, nil
+        }
+
+        err := ee.e.HandleEvent(ee.ectx, mk(path[i]))
+        if err != nil {
+            return ds.handled, err
+        }
+    }
+
+    return ds.handled, nil
+}
@@ -0,0 +1,135 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+var phaseNames = map[tui.EventPhase]string{
+    tui.PHASE_NONE: "none",
+    tui.PHASE_CAPTURE: "capture",
+    tui.PHASE_TARGET: "target",
+    tui.PHASE_BUBBLE: "bubble",
+}
+
+// Logs each phase it sees an event in, followed by "*" if the event was
+// already handled. In the given phases, it stops propagation, marks the
+// event handled or removes itself. (PHASE_NONE means never)
+type phaseProbe struct {
+    *tui.DefaultElement
+
+    name string
+    log *[]string
+
+    stop, handle, remove tui.EventPhase
+}
+
+func newPhaseProbe(name string, log *[]string) *phaseProbe {
+    return &phaseProbe{
+        DefaultElement: tui.NewDefaultElement(),
+        name: name,
+        log: log,
+        stop: tui.PHASE_NONE,
+        handle: tui.PHASE_NONE,
+        remove: tui.PHASE_NONE,
+    }
+}
+
+func (pp *phaseProbe) see(ectx *tui.ElementContext) error {
+    entry := pp.name + ":" + phaseNames[ectx.Phase()]
+    if ectx.EventHandled() {
+        entry += "*"
+    }
+
+    *pp.log = append(*pp.log, entry)
+
+    switch ectx.Phase() {
+    case pp.stop:
+        ectx.StopPropagation()
+    case pp.handle:
+        ectx.MarkHandled()
+    case pp.remove:
+        return ectx.DetachAndDeregister()
+    }
+
+    return nil
+}
+
+func (pp *phaseProbe) CaptureEvent(ectx *tui.ElementContext, ev tcell.Event) error {
+    return pp.see(ectx)
+}
+
+func (pp *phaseProbe) HandleEvent(ectx *tui.ElementContext, ev tcell.Event) error {
+    return pp.see(ectx)
+}
+
+func TestDispatchPhases(t *testing.T) {
+    cases := []struct {
+        name string
+        setup func (r, m, tg *phaseProbe)
+        handled bool
+        expected []string
+    }{
+        {"order", func (r, m, tg *phaseProbe) {},
+            false, []string{"r:capture", "m:capture", "t:target", "m:bubble", "r:bubble"}},
+        {"stop in capture", func (r, m, tg *phaseProbe) {
+            r.stop = tui.PHASE_CAPTURE
+        }, false, []string{"r:capture"}},
+        {"stop at target", func (r, m, tg *phaseProbe) {
+            tg.stop = tui.PHASE_TARGET
+        }, false, []string{"r:capture", "m:capture", "t:target"}},
+        {"stop in bubble", func (r, m, tg *phaseProbe) {
+            m.stop = tui.PHASE_BUBBLE
+        }, false, []string{"r:capture", "m:capture", "t:target", "m:bubble"}},
+
+        // Handling an event does not stop it.
+        {"handled in capture", func (r, m, tg *phaseProbe) {
+            m.handle = tui.PHASE_CAPTURE
+        }, true, []string{"r:capture", "m:capture", "t:target*", "m:bubble*", "r:bubble*"}},
+        {"handled at target", func (r, m, tg *phaseProbe) {
+            tg.handle = tui.PHASE_TARGET
+        }, true, []string{"r:capture", "m:capture", "t:target", "m:bubble*", "r:bubble*"}},
+
+        // Dispatch stops once the path is broken.
+        {"target removed", func (r, m, tg *phaseProbe) {
+            tg.remove = tui.PHASE_TARGET
+        }, false, []string{"r:capture", "m:capture", "t:target"}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            var log []string
+            r, m, tg := newPhaseProbe("r", &log), newPhaseProbe("m", &log), newPhaseProbe("t", &log)
+            tc.setup(r, m, tg)
+
+            // r holds m, which holds t.
+            var tid tui.ElementID
+            h := tuitest.New(t, 1, 10, func (env *tui.Environment) (tui.ElementID, error) {
+                rid, _ := env.Register(r)
+                mid, _ := env.Register(m)
+                tid, _ = env.Register(tg)
+
+                env.Attach(rid, mid)
+                env.Attach(mid, tid)
+
+                return rid, nil
+            })
+
+            env := h.Env()
+
+            handled, err := env.Dispatch(tid, tcell.NewEventInterrupt(nil))
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            if handled != tc.handled {
+                t.Errorf("expected handled=%t, got %t", tc.handled, handled)
+            }
+
+            expectLog(t, log, tc.expected...)
+        })
+    }
+}
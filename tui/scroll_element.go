@@ -0,0 +1,313 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Scroll Element --------------------------------------
+
+const (
+    scrollTrack = '░'
+    scrollThumb = '█'
+)
+
+// The largest size a scrollable dimmension of content can have.
+const maxScrollDim = 1 << 15
+
+// A scroll element holds one child which can be larger than the scroll
+// element itself. The child is laid out at its preferred size (but never
+// smaller than the visible area) and viewed through a viewport.
+//
+// Arrow keys, PageUp/PageDown, Home/End and the mouse wheel move the view.
+// (See HandleEvent) Non-routed events are forwarded to the child.
+type ScrollElement struct {
+    *DefaultElement
+
+    // Which directions content can scroll in.
+    // If a direction can't scroll, the child is constrained to the
+    // visible area in that direction.
+    vertical bool
+    horizontal bool
+
+    // If true, scrollbars are drawn for each scrollable direction.
+    scrollbars bool
+    scrollbarStyle tcell.Style
+
+    // Size of the visible area. (Excludes scrollbars)
+    viewRows, viewCols int
+
+    // Size of the child.
+    contentRows, contentCols int
+
+    // Offset of the visible area into the child.
+    rowOff, colOff int
+}
+
+func NewScrollElement(v bool, h bool, sb bool, sbs tcell.Style) *ScrollElement {
+    return &ScrollElement{
+        DefaultElement: NewDefaultElement(),
+        vertical: v,
+        horizontal: h,
+        scrollbars: sb,
+        scrollbarStyle: sbs,
+        viewRows: 0,
+        viewCols: 0,
+        contentRows: 0,
+        contentCols: 0,
+        rowOff: 0,
+        colOff: 0,
+    }
+}
+
+func ScrollElementF(v bool, h bool, sb bool, sbs tcell.Style, ef ElementFactory) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        eid, err := env.Register(NewScrollElement(v, h, sb, sbs))
+        if err != nil {
+            return -1, err
+        }
+
+        cid, err := ef(env)
+        if err != nil {
+            // This will never error.
+            env.Deregister(eid)
+            return -1, err
+        }
+
+        // This will never error.
+        env.Attach(eid, cid)
+        return eid, nil
+    }
+}
+
+// A focused scroll element receives scrolling keys directly.
+func (se *ScrollElement) AcceptsFocus() bool {
+    return true
+}
+
+func (se *ScrollElement) hasVerticalBar() bool {
+    return se.scrollbars && se.vertical
+}
+
+func (se *ScrollElement) hasHorizontalBar() bool {
+    return se.scrollbars && se.horizontal
+}
+
+// A scroll element would like to show all of its child, but can shrink
+// down to nothing in its scrollable directions.
+func (se *ScrollElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    cctx, _ := ectx.Child(0)
+    hint := cctx.Measure(cons)
+
+    if se.hasVerticalBar() {
+        hint.PrefCols++
+    }
+
+    if se.hasHorizontalBar() {
+        hint.PrefRows++
+    }
+
+    if se.vertical {
+        hint.MinRows = 0
+    }
+
+    if se.horizontal {
+        hint.MinCols = 0
+    }
+
+    hint.MaxRows = UNBOUNDED
+    hint.MaxCols = UNBOUNDED
+
+    return hint
+}
+
+// Keeps the offsets within the content.
+func (se *ScrollElement) clampOffsets() {
+    se.rowOff = max(min(se.rowOff, se.contentRows - se.viewRows), 0)
+    se.colOff = max(min(se.colOff, se.contentCols - se.viewCols), 0)
+}
+
+func (se *ScrollElement) updateViewport(ectx *ElementContext) error {
+    cctx, _ := ectx.Child(0)
+    return cctx.SetViewport(NewViewport(se.GetC(), se.GetR(),
+        se.colOff, se.rowOff, se.viewCols, se.viewRows))
+}
+
+func (se *ScrollElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := se.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    se.viewRows = rows
+    se.viewCols = cols
+
+    if se.hasVerticalBar() {
+        se.viewCols = max(se.viewCols - 1, 0)
+    }
+
+    if se.hasHorizontalBar() {
+        se.viewRows = max(se.viewRows - 1, 0)
+    }
+
+    cons := Constraints{MaxRows: se.viewRows, MaxCols: se.viewCols}
+    if se.vertical {
+        cons.MaxRows = maxScrollDim
+    }
+
+    if se.horizontal {
+        cons.MaxCols = maxScrollDim
+    }
+
+    cctx, _ := ectx.Child(0)
+    hint := cctx.Measure(cons)
+
+    se.contentRows = max(hint.PrefRows, se.viewRows)
+    se.contentCols = max(hint.PrefCols, se.viewCols)
+
+    se.clampOffsets()
+
+    // The child lives in its own coordinate space.
+    err = cctx.ForwardResize(0, 0, se.contentRows, se.contentCols)
+    if err != nil {
+        return err
+    }
+
+    return se.updateViewport(ectx)
+}
+
+// Moves the visible area by the given amounts.
+func (se *ScrollElement) scrollBy(ectx *ElementContext, dRows, dCols int) error {
+    oldRowOff, oldColOff := se.rowOff, se.colOff
+
+    if se.vertical {
+        se.rowOff += dRows
+    }
+
+    if se.horizontal {
+        se.colOff += dCols
+    }
+
+    se.clampOffsets()
+
+    if se.rowOff == oldRowOff && se.colOff == oldColOff {
+        return nil
+    }
+
+    // Scrollbars must be redrawn.
+    ectx.SetDrawFlag()
+
+    return se.updateViewport(ectx)
+}
+
+// Returns the amount to scroll by for the given event.
+// ok is false if the event is not a scrolling event.
+func (se *ScrollElement) scrollAmount(ev tcell.Event) (int, int, bool) {
+    switch tev := ev.(type) {
+    case *MouseEvent:
+        if tev.Action() != MOUSE_WHEEL {
+            break
+        }
+
+        buttons := tev.Buttons()
+
+        switch {
+        case buttons & tcell.WheelUp != 0:
+            return -1, 0, true
+        case buttons & tcell.WheelDown != 0:
+            return 1, 0, true
+        case buttons & tcell.WheelLeft != 0:
+            return 0, -1, true
+        case buttons & tcell.WheelRight != 0:
+            return 0, 1, true
+        }
+
+    case *tcell.EventKey:
+        switch tev.Key() {
+        case tcell.KeyUp:
+            return -1, 0, true
+        case tcell.KeyDown:
+            return 1, 0, true
+        case tcell.KeyLeft:
+            return 0, -1, true
+        case tcell.KeyRight:
+            return 0, 1, true
+        case tcell.KeyPgUp:
+            return -max(se.viewRows - 1, 1), 0, true
+        case tcell.KeyPgDn:
+            return max(se.viewRows - 1, 1), 0, true
+        case tcell.KeyHome:
+            return -se.rowOff, -se.colOff, true
+        case tcell.KeyEnd:
+            return se.contentRows, 0, true
+        }
+    }
+
+    return 0, 0, false
+}
+
+// Scrolling events which reach the scroll element unhandled (either 
+// directly or by bubbling up from its descendants) move the view.
+func (se *ScrollElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    dRows, dCols, ok := se.scrollAmount(ev)
+    if ok && !ectx.EventHandled() {
+        ectx.MarkHandled()
+        return se.scrollBy(ectx, dRows, dCols)
+    }
+
+    if IsRoutedEvent(ev) {
+        return nil
+    }
+
+    cctx, _ := ectx.Child(0)
+    return cctx.ForwardEvent(ev)
+}
+
+// Returns the offset and length of a scrollbar's thumb given
+// the length of the track.
+func thumbBounds(track int, view int, content int, off int) (int, int) {
+    if content <= view {
+        return 0, track
+    }
+
+    length := max((track * view) / content, 1)
+    pos := (off * (track - length)) / (content - view)
+
+    return pos, length
+}
+
+// The child draws the visible area, this just draws the scrollbars.
+func (se *ScrollElement) Draw(cv *Canvas) {
+    if se.hasVerticalBar() && se.GetCols() > 0 {
+        c := se.GetCols() - 1
+        pos, length := thumbBounds(se.viewRows, se.viewRows, se.contentRows, se.rowOff)
+
+        for i := 0; i < se.viewRows; i++ {
+            ru := scrollTrack
+            if pos <= i && i < pos + length {
+                ru = scrollThumb
+            }
+
+            cv.SetContentAt(i, c, ru, nil, se.scrollbarStyle)
+        }
+    }
+
+    if se.hasHorizontalBar() && se.GetRows() > 0 {
+        r := se.GetRows() - 1
+        pos, length := thumbBounds(se.viewCols, se.viewCols, se.contentCols, se.colOff)
+
+        for j := 0; j < se.viewCols; j++ {
+            ru := scrollTrack
+            if pos <= j && j < pos + length {
+                ru = scrollThumb
+            }
+
+            cv.SetContentAt(r, j, ru, nil, se.scrollbarStyle)
+        }
+    }
+
+    // Corner where both scrollbars meet.
+    if se.hasVerticalBar() && se.hasHorizontalBar() && se.GetRows() > 0 && se.GetCols() > 0 {
+        cv.SetContentAt(se.GetRows() - 1, se.GetCols() - 1,
+            ' ', nil, se.scrollbarStyle)
+    }
+}
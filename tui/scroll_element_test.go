@@ -0,0 +1,77 @@
+package tui_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+var errFactory = errors.New("factory failed")
+
+func failingF(env *tui.Environment) (tui.ElementID, error) {
+    return -1, errFactory
+}
+
+// Containers register nothing when one of their children can't be created.
+func TestContainerFactoryFailure(t *testing.T) {
+    cases := []struct {
+        name string
+        ef tui.ElementFactory
+    }{
+        {"scroll", tui.ScrollElementF(true, true, true, plain, failingF)},
+        {"divided", tui.DividedElementF(true, false, plain,
+            tui.FlexDivision(1, tui.TextElementF(plain, "a")),
+            tui.FlexDivision(1, failingF))},
+        {"grid", tui.GridElementF(
+            []tui.DivisionSpec{tui.NewFlexSpec(1)},
+            []tui.DivisionSpec{tui.NewFlexSpec(1), tui.NewFlexSpec(1)}, plain,
+            tui.GridItemAt(0, 0, tui.TextElementF(plain, "a")),
+            tui.GridItemAt(0, 1, failingF))},
+        {"tabs", tui.TabsElementF(plain, blue,
+            tui.NewTab("One", tui.TextElementF(plain, "a")),
+            tui.NewTab("Two", failingF))},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            h := tuitest.New(t, 4, 20, tui.TextElementF(plain, "root"))
+            env := h.Env()
+            fill := env.Fill()
+
+            _, err := env.CreateAndRegister(tc.ef)
+            if !errors.Is(err, errFactory) {
+                t.Fatalf("expected %v, got %v", errFactory, err)
+            }
+
+            if env.Fill() != fill {
+                t.Errorf("expected %d elements registered, got %d", fill, env.Fill())
+            }
+        })
+    }
+}
+
+// The scroll element can't be registered alongside its child.
+func TestScrollElementAtCapacity(t *testing.T) {
+    s := tcell.NewSimulationScreen("UTF-8")
+    err := s.Init()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    t.Cleanup(s.Fini)
+
+    env := tui.NewEnvironment(s, 1, 10 * time.Millisecond)
+
+    _, err = env.CreateAndRegister(tui.ScrollElementF(true, true, true, plain, tui.TextElementF(plain, "a")))
+    if err == nil {
+        t.Fatal("expected an error at capacity")
+    }
+
+    if env.Fill() != 0 {
+        t.Errorf("expected nothing registered, got %d elements", env.Fill())
+    }
+}
@@ -0,0 +1,655 @@
+package tui
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Table Element --------------------------------------
+
+// A table element shows the cells of a table source in columns under a
+// header row. The header stays put while the body scrolls.
+//
+// Column widths are decided by division specs, just like the divisions
+// of a divided element. (See division.go) Auto specs are given the width
+// of the widest cell among the first few rows.
+//
+// Like a list, only the visible rows are ever asked for. Sorting however
+// must look at every row of the sorted column.
+//
+// Keys:
+//      Up/Down             Move the selection.
+//      PageUp/PageDown     Move the selection by a page.
+//      Home/End            Select the first/last row.
+//      Enter               Activate the selected row.
+//      1-9                 Sort by the given column. Sorting by the same
+//                          column again reverses the order.
+//
+// Clicking a header sorts by its column. Clicking a row selects it,
+// clicking the selected row activates it.
+
+// A table source provides the cells of a table. Cells carry their own
+// styles. (See StyledText)
+type TableSource interface {
+    Len() int
+    Cell(row, col int) StyledText
+}
+
+// Space between columns.
+const tableColumnGap = 1
+
+const (
+    sortAscending = '▲'
+    sortDescending = '▼'
+)
+
+type TableColumn struct {
+    title string
+    spec DivisionSpec
+    align HAlign
+
+    // Returns a negative number if a comes before b, a positive number if
+    // b comes before a, and 0 if they are equal.
+    // nil if the column can't be sorted.
+    compare func (a, b StyledText) int
+}
+
+// By default, a column is left aligned and sorted by compareCells.
+func NewTableColumn(title string, spec DivisionSpec) TableColumn {
+    return TableColumn{
+        title: title,
+        spec: spec,
+        align: ALIGN_LEFT,
+        compare: compareCells,
+    }
+}
+
+func (tc TableColumn) WithAlign(h HAlign) TableColumn {
+    tc.align = h
+    return tc
+}
+
+func (tc TableColumn) WithCompare(fn func (a, b StyledText) int) TableColumn {
+    tc.compare = fn
+    return tc
+}
+
+func (tc TableColumn) Unsortable() TableColumn {
+    tc.compare = nil
+    return tc
+}
+
+// Cells which are both numbers are compared numerically, otherwise cells
+// are compared by their text, ignoring case.
+func compareCells(a, b StyledText) int {
+    as, bs := strings.TrimSpace(a.String()), strings.TrimSpace(b.String())
+
+    an, aErr := strconv.ParseFloat(as, 64)
+    bn, bErr := strconv.ParseFloat(bs, 64)
+
+    if aErr == nil && bErr == nil {
+        switch {
+        case an < bn:
+            return -1
+        case an > bn:
+            return 1
+        default:
+            return 0
+        }
+    }
+
+    return strings.Compare(strings.ToLower(as), strings.ToLower(bs))
+}
+
+type TableOptions struct {
+    headerStyle *tcell.Style
+    selectedStyle *tcell.Style
+
+    onSelect func (*ElementContext, int) error
+    onActivate func (*ElementContext, int) error
+}
+
+func NewTableOptions() TableOptions {
+    return TableOptions{
+        headerStyle: nil,
+        selectedStyle: nil,
+        onSelect: nil,
+        onActivate: nil,
+    }
+}
+
+// By default, the header is drawn bold.
+func (to TableOptions) WithHeaderStyle(s tcell.Style) TableOptions {
+    to.headerStyle = &s
+    return to
+}
+
+// By default, the selected row is drawn reversed.
+func (to TableOptions) WithSelectedStyle(s tcell.Style) TableOptions {
+    to.selectedStyle = &s
+    return to
+}
+
+// Called with the source row of the newly selected row every time the
+// user moves the selection.
+func (to TableOptions) OnSelect(fn func (*ElementContext, int) error) TableOptions {
+    to.onSelect = fn
+    return to
+}
+
+// Called with the source row of the selected row when it is activated.
+func (to TableOptions) OnActivate(fn func (*ElementContext, int) error) TableOptions {
+    to.onActivate = fn
+    return to
+}
+
+type TableElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    headerStyle tcell.Style
+    selectedStyle tcell.Style
+
+    opts TableOptions
+
+    columns []TableColumn
+    src TableSource
+
+    // order[i] is the source row displayed at row i.
+    order []int
+
+    // -1 when not sorted.
+    sortCol int
+    sortDesc bool
+
+    // Index into order, -1 when the table is empty.
+    selected int
+
+    // The first visible row.
+    rowOff int
+
+    widths []int
+
+    // Set when a press lands on the row which was already selected.
+    pressedSelected bool
+}
+
+func NewTableElement(s tcell.Style, columns []TableColumn, src TableSource, opts TableOptions) *TableElement {
+    hs := s.Bold(true)
+    if opts.headerStyle != nil {
+        hs = *opts.headerStyle
+    }
+
+    ss := s.Reverse(true)
+    if opts.selectedStyle != nil {
+        ss = *opts.selectedStyle
+    }
+
+    te := &TableElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        headerStyle: hs,
+        selectedStyle: ss,
+        opts: opts,
+        columns: columns,
+        src: src,
+        order: nil,
+        sortCol: -1,
+        sortDesc: false,
+        selected: -1,
+        rowOff: 0,
+        widths: make([]int, len(columns)),
+        pressedSelected: false,
+    }
+
+    te.resetOrder()
+    return te
+}
+
+func TableElementF(s tcell.Style, columns []TableColumn, src TableSource, opts TableOptions) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        return env.Register(NewTableElement(s, columns, src, opts))
+    }
+}
+
+func (te *TableElement) AcceptsFocus() bool {
+    return true
+}
+
+// Rebuilds order from the source, keeping the selected source row
+// selected where possible.
+func (te *TableElement) resetOrder() {
+    selRow := -1
+    if te.selected != -1 {
+        selRow = te.order[te.selected]
+    }
+
+    n := te.src.Len()
+
+    te.order = make([]int, n)
+    for i := range te.order {
+        te.order[i] = i
+    }
+
+    te.sortRows()
+    te.selected = te.displayIndex(selRow)
+
+    if te.selected == -1 && n > 0 {
+        te.selected = 0
+    }
+}
+
+// Returns where the given source row is displayed, or -1.
+func (te *TableElement) displayIndex(row int) int {
+    for i, r := range te.order {
+        if r == row {
+            return i
+        }
+    }
+
+    return -1
+}
+
+func (te *TableElement) sortRows() {
+    if te.sortCol == -1 {
+        return
+    }
+
+    col := te.sortCol
+    compare := te.columns[col].compare
+
+    // Each cell is only asked for once.
+    cells := make([]StyledText, len(te.order))
+    for _, r := range te.order {
+        cells[r] = te.src.Cell(r, col)
+    }
+
+    sort.SliceStable(te.order, func (i, j int) bool {
+        c := compare(cells[te.order[i]], cells[te.order[j]])
+        if te.sortDesc {
+            return c > 0
+        }
+
+        return c < 0
+    })
+}
+
+func (te *TableElement) GetSource() TableSource {
+    return te.src
+}
+
+// Replaces the source. The current sort is kept.
+func (te *TableElement) SetSource(ectx *ElementContext, src TableSource) {
+    te.src = src
+    te.selected = -1
+    te.rowOff = 0
+    te.resetOrder()
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+}
+
+// Should be called after the source's rows change. The rows are sorted
+// again and the selection is kept where possible.
+func (te *TableElement) Refresh(ectx *ElementContext) {
+    te.resetOrder()
+    te.scrollToSelected()
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+}
+
+// Returns the source row of the selected row, or -1 if the table is empty.
+func (te *TableElement) GetSelected() int {
+    if te.selected == -1 {
+        return -1
+    }
+
+    return te.order[te.selected]
+}
+
+// Selects the given source row.
+// NOTE: This does not call the select callback.
+func (te *TableElement) SetSelected(ectx *ElementContext, row int) {
+    if i := te.displayIndex(row); i != -1 {
+        te.moveSelection(i)
+        ectx.SetDrawFlag()
+    }
+}
+
+// Sorts by the given column. If the table is already sorted by the
+// column, the order is reversed.
+func (te *TableElement) SortBy(ectx *ElementContext, col int) {
+    if col < 0 || len(te.columns) <= col || te.columns[col].compare == nil {
+        return
+    }
+
+    if te.sortCol == col {
+        te.sortDesc = !te.sortDesc
+    } else {
+        te.sortCol = col
+        te.sortDesc = false
+    }
+
+    te.resetOrder()
+    te.scrollToSelected()
+
+    ectx.SetDrawFlag()
+}
+
+// The number of rows available to the body.
+func (te *TableElement) bodyRows() int {
+    return max(te.GetRows() - 1, 0)
+}
+
+// Moves the selection, returns true if it changed.
+func (te *TableElement) moveSelection(i int) bool {
+    n := len(te.order)
+    if n == 0 {
+        return false
+    }
+
+    i = max(min(i, n - 1), 0)
+    if i == te.selected {
+        return false
+    }
+
+    te.selected = i
+    te.scrollToSelected()
+
+    return true
+}
+
+// Moves the selection and calls the select callback if it changed.
+func (te *TableElement) selectRow(ectx *ElementContext, i int) error {
+    if !te.moveSelection(i) {
+        return nil
+    }
+
+    ectx.SetDrawFlag()
+
+    if te.opts.onSelect != nil {
+        return te.opts.onSelect(ectx, te.order[te.selected])
+    }
+
+    return nil
+}
+
+func (te *TableElement) activate(ectx *ElementContext) error {
+    if te.selected == -1 || te.opts.onActivate == nil {
+        return nil
+    }
+
+    return te.opts.onActivate(ectx, te.order[te.selected])
+}
+
+// Keeps the offset within the rows.
+func (te *TableElement) clampOffset() {
+    te.rowOff = max(min(te.rowOff, len(te.order) - te.bodyRows()), 0)
+}
+
+func (te *TableElement) scrollToSelected() {
+    if te.selected < te.rowOff {
+        te.rowOff = te.selected
+    }
+
+    if te.bodyRows() > 0 && te.selected >= te.rowOff + te.bodyRows() {
+        te.rowOff = te.selected - te.bodyRows() + 1
+    }
+
+    te.clampOffset()
+}
+
+// Returns the preferred width of each column. Only the first few rows
+// are measured. (See listMeasureLimit)
+func (te *TableElement) columnPrefs() []int {
+    tabWidth := NewTextFormat().tabWidth
+    prefs := make([]int, len(te.columns))
+
+    for j, col := range te.columns {
+        // Leave room for the sort indicator.
+        prefs[j] = toLine(PlainText(col.title, te.headerStyle), tabWidth).width + 2
+    }
+
+    for i := 0; i < min(te.src.Len(), listMeasureLimit); i++ {
+        for j := range te.columns {
+            prefs[j] = max(prefs[j], toLine(te.src.Cell(i, j), tabWidth).width)
+        }
+    }
+
+    return prefs
+}
+
+// A table would like to show all of its rows with every column at its
+// preferred width.
+func (te *TableElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    cols := 0
+    for _, p := range te.columnPrefs() {
+        cols += p
+    }
+
+    if len(te.columns) > 0 {
+        cols += tableColumnGap * (len(te.columns) - 1)
+    }
+
+    return SizeHint{
+        PrefRows: 1 + te.src.Len(),
+        PrefCols: cols,
+        MinRows: 1,
+        MinCols: 0,
+        MaxRows: UNBOUNDED,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (te *TableElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := te.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    if len(te.columns) == 0 {
+        return nil
+    }
+
+    specs := make([]DivisionSpec, len(te.columns))
+    for j, col := range te.columns {
+        specs[j] = col.spec
+    }
+
+    avail := max(cols - tableColumnGap * (len(te.columns) - 1), 0)
+
+    te.widths, err = mapToDims(avail, specs, te.columnPrefs())
+    if err != nil {
+        return err
+    }
+
+    te.scrollToSelected()
+    return nil
+}
+
+// Returns the column at the given x, or -1.
+func (te *TableElement) columnAt(x int) int {
+    c := 0
+    for j, w := range te.widths {
+        if c <= x && x < c + w {
+            return j
+        }
+
+        c += w + tableColumnGap
+    }
+
+    return -1
+}
+
+// Performs the action of the given key.
+// handled is false if the table does not use the key.
+func (te *TableElement) handleKey(ectx *ElementContext, ev *tcell.EventKey) (bool, error) {
+    page := max(te.bodyRows() - 1, 1)
+
+    switch ev.Key() {
+    case tcell.KeyUp:
+        return true, te.selectRow(ectx, te.selected - 1)
+    case tcell.KeyDown:
+        return true, te.selectRow(ectx, te.selected + 1)
+    case tcell.KeyPgUp:
+        return true, te.selectRow(ectx, te.selected - page)
+    case tcell.KeyPgDn:
+        return true, te.selectRow(ectx, te.selected + page)
+    case tcell.KeyHome:
+        return true, te.selectRow(ectx, 0)
+    case tcell.KeyEnd:
+        return true, te.selectRow(ectx, len(te.order) - 1)
+    case tcell.KeyEnter:
+        return true, te.activate(ectx)
+
+    case tcell.KeyRune:
+        ru := ev.Rune()
+        if ru < '1' || '9' < ru || int(ru - '1') >= len(te.columns) {
+            return false, nil
+        }
+
+        te.SortBy(ectx, int(ru - '1'))
+        return true, nil
+    }
+
+    return false, nil
+}
+
+func (te *TableElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    if ectx.EventHandled() {
+        return nil
+    }
+
+    switch tev := ev.(type) {
+    case *MouseEvent:
+        return te.handleMouse(ectx, tev)
+
+    case *tcell.EventKey:
+        handled, err := te.handleKey(ectx, tev)
+        if handled {
+            ectx.MarkHandled()
+        }
+
+        return err
+    }
+
+    return nil
+}
+
+func (te *TableElement) handleMouse(ectx *ElementContext, ev *MouseEvent) error {
+    x, y := ev.Position()
+    i := te.rowOff + y - 1
+
+    switch ev.Action() {
+    case MOUSE_PRESS:
+        ectx.MarkHandled()
+
+        err := ectx.Focus()
+        if err != nil {
+            return err
+        }
+
+        if y == 0 {
+            te.SortBy(ectx, te.columnAt(x))
+            return nil
+        }
+
+        if i >= len(te.order) {
+            return nil
+        }
+
+        te.pressedSelected = i == te.selected
+        return te.selectRow(ectx, i)
+
+    case MOUSE_CLICK:
+        ectx.MarkHandled()
+
+        if y > 0 && te.pressedSelected && i == te.selected {
+            return te.activate(ectx)
+        }
+
+    case MOUSE_WHEEL:
+        buttons := ev.Buttons()
+
+        switch {
+        case buttons & tcell.WheelUp != 0:
+            te.rowOff -= wheelRows
+        case buttons & tcell.WheelDown != 0:
+            te.rowOff += wheelRows
+        default:
+            return nil
+        }
+
+        // Scrolling does not move the selection.
+        ectx.MarkHandled()
+        te.clampOffset()
+        ectx.SetDrawFlag()
+    }
+
+    return nil
+}
+
+// Draws a cell in the given column of row r. If style is not nil, it
+// replaces the cell's own styles.
+func (te *TableElement) drawCell(cv *Canvas, r int, c int, j int, st StyledText, style *tcell.Style) {
+    w := te.widths[j]
+    line := truncateLine(toLine(st, NewTextFormat().tabWidth), w, false)
+
+    if style != nil {
+        for k := range line.glyphs {
+            line.glyphs[k].style = *style
+        }
+    }
+
+    align := te.columns[j].align
+    left := alignOffset(w, line.width, align == ALIGN_CENTER, align == ALIGN_RIGHT)
+
+    drawLine(cv.Sub(r, c, 1, w), 0, left, line)
+}
+
+func (te *TableElement) Draw(cv *Canvas) {
+    cv.Fill(' ', te.style)
+
+    if cv.Rows() == 0 {
+        return
+    }
+
+    cv.FillRect(0, 0, 1, cv.Cols(), ' ', te.headerStyle)
+
+    c := 0
+    for j, col := range te.columns {
+        title := col.title
+        if j == te.sortCol {
+            indicator := sortAscending
+            if te.sortDesc {
+                indicator = sortDescending
+            }
+
+            title += " " + string(indicator)
+        }
+
+        te.drawCell(cv, 0, c, j, PlainText(title, te.headerStyle), nil)
+        c += te.widths[j] + tableColumnGap
+    }
+
+    for r := 1; r < cv.Rows() && te.rowOff + r - 1 < len(te.order); r++ {
+        i := te.rowOff + r - 1
+        row := te.order[i]
+
+        var style *tcell.Style
+        if i == te.selected {
+            style = &te.selectedStyle
+            cv.FillRect(r, 0, 1, cv.Cols(), ' ', te.selectedStyle)
+        }
+
+        c := 0
+        for j := range te.columns {
+            te.drawCell(cv, r, c, j, te.src.Cell(row, j), style)
+            c += te.widths[j] + tableColumnGap
+        }
+    }
+}
@@ -0,0 +1,125 @@
+package tui_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+type rowSource [][]string
+
+func (rs rowSource) Len() int {
+    return len(rs)
+}
+
+func (rs rowSource) Cell(row, col int) tui.StyledText {
+    return tui.PlainText(rs[row][col], plain)
+}
+
+func newTable(src tui.TableSource, opts tui.TableOptions) *tui.TableElement {
+    columns := []tui.TableColumn{
+        tui.NewTableColumn("Name", tui.NewFlexSpec(1)),
+        tui.NewTableColumn("Size", tui.NewFlexSpec(1)),
+    }
+
+    return tui.NewTableElement(plain, columns, src, opts)
+}
+
+func newTableHarness(t *testing.T, src tui.TableSource, opts tui.TableOptions) (*tuitest.Harness, *tui.TableElement) {
+    te := newTable(src, opts)
+
+    // Clicks the first row to focus without sorting.
+    h := newFocusedHarness(t, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(te)
+    }, 0, 1)
+
+    return h, te
+}
+
+func TestTableEmptySource(t *testing.T) {
+    calls := 0
+    count := func (ectx *tui.ElementContext, i int) error {
+        calls++
+        return nil
+    }
+
+    te := newTable(rowSource{}, tui.NewTableOptions().OnSelect(count).OnActivate(count))
+
+    testEmptyNavigation(t, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(te)
+    }, func () bool {
+        return te.GetSelected() != -1
+    }, &calls)
+}
+
+func TestTableSortKeepsSelection(t *testing.T) {
+    src := rowSource{
+        {"b", "2"},
+        {"c", "1"},
+        {"a", "3"},
+    }
+
+    h, te := newTableHarness(t, src, tui.NewTableOptions())
+
+    // The click selected the first row, "b".
+    if te.GetSelected() != 0 {
+        t.Fatalf("expected row 0 selected, got %d", te.GetSelected())
+    }
+
+    h.Type("1")
+    h.Key(tcell.KeyUp, tcell.ModNone)
+
+    // Sorted by name, "a" comes before "b".
+    if te.GetSelected() != 2 {
+        t.Errorf("expected row 2 selected, got %d", te.GetSelected())
+    }
+}
+
+// Clicking a header sorts by its column. The 20 columns are split into a
+// 10 column "Name", a 1 column gap and a 9 column "Size".
+func TestTableHeaderClick(t *testing.T) {
+    src := rowSource{
+        {"b", "2"},
+        {"c", "1"},
+        {"a", "3"},
+    }
+
+    cases := []struct {
+        name string
+        clicks []int
+        header string
+        first string
+    }{
+        {"name", []int{0}, "Name ▲     Size", "a          3"},
+        {"name end", []int{9}, "Name ▲     Size", "a          3"},
+        {"name again", []int{0, 5}, "Name ▼     Size", "c          1"},
+        {"size", []int{11}, "Name       Size ▲", "c          1"},
+        {"size end", []int{19}, "Name       Size ▲", "c          1"},
+        {"size again", []int{11, 15}, "Name       Size ▼", "a          3"},
+        {"switch column", []int{0, 0, 11}, "Name       Size ▲", "c          1"},
+        {"gap", []int{10}, "Name       Size", "b          2"},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            h, _ := newTableHarness(t, src, tui.NewTableOptions())
+
+            for _, x := range tc.clicks {
+                h.Click(x, 0)
+            }
+
+            lines := strings.Split(h.Text(), "\n")
+
+            if header := strings.TrimRight(lines[0], " "); header != tc.header {
+                t.Errorf("expected header %q, got %q", tc.header, header)
+            }
+
+            if first := strings.TrimRight(lines[1], " "); first != tc.first {
+                t.Errorf("expected first row %q, got %q", tc.first, first)
+            }
+        })
+    }
+}
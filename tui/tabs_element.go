@@ -0,0 +1,443 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Tabs Element --------------------------------------
+
+// A tabs element holds any number of children, but only shows one of them
+// (the active tab) below a strip of tab titles.
+//
+// Inactive tabs stay registered and attached, but are hidden. (See
+// Environment.SetHidden) They are not drawn and are not forwarded events,
+// so they also receive no ticks.
+//
+// Keys:
+//      Ctrl-PageUp/Ctrl-PageDown   Switch to the previous/next tab.
+//      Alt-1 to Alt-9              Switch to the given tab.
+//      Left/Right                  Switch tabs while the tabs element
+//                                  itself is focused.
+//
+// Clicking a title switches to its tab.
+
+// The child attribute key which all children of a tabs element must have.
+// Its value is the tab's title. (A string)
+const TAB_TITLE_ATTR = "tab-title"
+
+const tabSeparator = '│'
+
+type TabsElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    activeStyle tcell.Style
+
+    // -1 when there are no tabs.
+    active int
+
+    // The title of each tab, where each title starts in the strip, and
+    // where the last one ends. Calculated during resize.
+    titles []string
+    titleCols []int
+}
+
+func NewTabsElement(s tcell.Style, as tcell.Style) *TabsElement {
+    return &TabsElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        activeStyle: as,
+        active: -1,
+        titles: make([]string, 0),
+        titleCols: make([]int, 0),
+    }
+}
+
+// A tab pairs an element factory with the title its element should be
+// given inside a tabs element.
+type Tab struct {
+    title string
+    ef ElementFactory
+}
+
+func NewTab(title string, ef ElementFactory) Tab {
+    return Tab{
+        title: title,
+        ef: ef,
+    }
+}
+
+// The first tab starts out active.
+func TabsElementF(s tcell.Style, as tcell.Style, tabs ...Tab) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        te := NewTabsElement(s, as)
+
+        eid, err := env.Register(te)
+        if err != nil {
+            return -1, err
+        }
+
+        for i, tab := range tabs {
+            cid, err := tab.ef(env)
+            if err != nil {
+                // Earlier children are deregistered along with the
+                // parent. This will never error.
+                env.Deregister(eid)
+                return -1, err
+            }
+
+            // These will never error.
+            index, _ := env.Attach(eid, cid)
+            env.SetChildAttr(eid, index, TAB_TITLE_ATTR, tab.title)
+
+            if i > 0 {
+                env.SetHidden(cid, true)
+            }
+        }
+
+        if len(tabs) > 0 {
+            te.active = 0
+        }
+
+        return eid, nil
+    }
+}
+
+// Creates, registers and attaches a new tab at the end of the given tabs
+// element's context. The new tab is not made active unless it is the
+// only tab.
+func AttachTab(ectx *ElementContext, tab Tab) (int, error) {
+    index, err := ectx.CreateRegisterAndAttach(tab.ef)
+    if err != nil {
+        return -1, fmt.Errorf("AttachTab: %w", err)
+    }
+
+    // These will never error.
+    ectx.SetChildAttr(index, TAB_TITLE_ATTR, tab.title)
+
+    // A first tab is made active during the next layout.
+    if index > 0 {
+        cctx, _ := ectx.Child(index)
+        cctx.SetHidden(true)
+    }
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+
+    return index, nil
+}
+
+// Detaches and deregisters the tab at the given index. If it was active,
+// the tab after it (or before it, if it was last) becomes active.
+func (te *TabsElement) RemoveTab(ectx *ElementContext, index int) error {
+    cctx, err := ectx.Child(index)
+    if err != nil {
+        return fmt.Errorf("RemoveTab: %w", err)
+    }
+
+    refocus := cctx.FocusWithin()
+
+    err = cctx.DetachAndDeregister()
+    if err != nil {
+        return fmt.Errorf("RemoveTab: %w", err)
+    }
+
+    n := ectx.NumChildren()
+
+    switch {
+    case n == 0:
+        te.active = -1
+    case index < te.active:
+        te.active--
+    case index == te.active:
+        te.active = min(index, n - 1)
+
+        nctx, _ := ectx.Child(te.active)
+        nctx.SetHidden(false)
+    }
+
+    if refocus {
+        err = ectx.Focus()
+        if err != nil {
+            return fmt.Errorf("RemoveTab: %w", err)
+        }
+    }
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+
+    return nil
+}
+
+// Returns -1 if there are no tabs.
+func (te *TabsElement) GetActive() int {
+    return te.active
+}
+
+// Makes the tab at the given index active. If focus was within the
+// previously active tab, the tabs element itself takes focus.
+func (te *TabsElement) SetActive(ectx *ElementContext, index int) error {
+    if index < 0 || ectx.NumChildren() <= index {
+        return fmt.Errorf("SetActive: Tab index out of bounds: %d", index)
+    }
+
+    if index == te.active {
+        return nil
+    }
+
+    refocus := false
+
+    if te.active != -1 {
+        octx, _ := ectx.Child(te.active)
+        refocus = octx.FocusWithin()
+
+        err := octx.SetHidden(true)
+        if err != nil {
+            return err
+        }
+    }
+
+    te.active = index
+
+    nctx, _ := ectx.Child(index)
+    err := nctx.SetHidden(false)
+    if err != nil {
+        return err
+    }
+
+    ectx.SetDrawFlag()
+
+    if refocus {
+        return ectx.Focus()
+    }
+
+    return nil
+}
+
+// Moves the active tab by d, wrapping around at the ends.
+func (te *TabsElement) cycle(ectx *ElementContext, d int) error {
+    n := ectx.NumChildren()
+    if n == 0 {
+        return nil
+    }
+
+    return te.SetActive(ectx, ((te.active + d) % n + n) % n)
+}
+
+// A focused tabs element receives tab switching keys directly.
+func (te *TabsElement) AcceptsFocus() bool {
+    return true
+}
+
+func (te *TabsElement) title(ectx *ElementContext, index int) (string, error) {
+    val, err := ectx.GetChildAttr(index, TAB_TITLE_ATTR)
+    if err != nil {
+        return "", err
+    }
+
+    t, ok := val.(string)
+    if !ok {
+        return "", fmt.Errorf("tab-title has incorrect type: %d", index)
+    }
+
+    return t, nil
+}
+
+// Every tab is given the space below the strip.
+//
+// NOTE: Titles are read here, so a changed title attribute is only shown
+// after the next layout.
+func (te *TabsElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := te.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    if te.active == -1 && ectx.NumChildren() > 0 {
+        te.active = 0
+    }
+
+    te.titles = te.titles[:0]
+    te.titleCols = te.titleCols[:0]
+
+    col := 0
+    for i := 0; i < ectx.NumChildren(); i++ {
+        t, err := te.title(ectx, i)
+        if err != nil {
+            return fmt.Errorf("Resize: %w", err)
+        }
+
+        te.titles = append(te.titles, t)
+        te.titleCols = append(te.titleCols, col)
+        col += toLine(PlainText(t, te.style), 1).width + 3
+    }
+
+    te.titleCols = append(te.titleCols, col)
+
+    for i := 0; i < ectx.NumChildren(); i++ {
+        cctx, _ := ectx.Child(i)
+
+        err = cctx.ForwardResize(r + 1, c, max(rows - 1, 0), cols)
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// A tabs element would like enough room for its strip above its
+// largest tab.
+func (te *TabsElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    hint := te.DefaultElement.Measure(ectx, cons)
+
+    for i := 0; i < ectx.NumChildren(); i++ {
+        cctx, _ := ectx.Child(i)
+        chint := cctx.Measure(Constraints{
+            MaxRows: max(cons.MaxRows - 1, 0),
+            MaxCols: cons.MaxCols,
+        })
+
+        hint.PrefRows = max(hint.PrefRows, chint.PrefRows)
+        hint.PrefCols = max(hint.PrefCols, chint.PrefCols)
+
+        if t, err := te.title(ectx, i); err == nil {
+            hint.PrefCols = max(hint.PrefCols, toLine(PlainText(t, te.style), 1).width + 2)
+        }
+    }
+
+    hint.PrefRows++
+    hint.MinRows = 1
+
+    return hint
+}
+
+// Returns how far the strip is shifted left so the active title fits.
+func (te *TabsElement) stripOffset() int {
+    if te.active == -1 {
+        return 0
+    }
+
+    return max(te.titleCols[te.active + 1] - 1 - te.GetCols(), 0)
+}
+
+// Returns the tab whose title is at column x of the strip, or -1.
+func (te *TabsElement) tabAt(x int) int {
+    x += te.stripOffset()
+
+    for i := 0; i + 1 < len(te.titleCols); i++ {
+        if te.titleCols[i] <= x && x < te.titleCols[i + 1] - 1 {
+            return i
+        }
+    }
+
+    return -1
+}
+
+// Performs the action of the given key.
+// handled is false if the tabs element does not use the key.
+func (te *TabsElement) handleKey(ectx *ElementContext, ev *tcell.EventKey) (bool, error) {
+    mods := ev.Modifiers()
+
+    switch {
+    case ev.Key() == tcell.KeyPgUp && mods & tcell.ModCtrl != 0:
+        return true, te.cycle(ectx, -1)
+    case ev.Key() == tcell.KeyPgDn && mods & tcell.ModCtrl != 0:
+        return true, te.cycle(ectx, 1)
+
+    case ev.Key() == tcell.KeyRune && mods & tcell.ModAlt != 0 &&
+        '1' <= ev.Rune() && ev.Rune() <= '9':
+        index := int(ev.Rune() - '1')
+        if index >= ectx.NumChildren() {
+            return false, nil
+        }
+
+        return true, te.SetActive(ectx, index)
+
+    // Arrows only switch tabs when nothing inside the tab is focused.
+    case ev.Key() == tcell.KeyLeft && ectx.IsFocused():
+        return true, te.cycle(ectx, -1)
+    case ev.Key() == tcell.KeyRight && ectx.IsFocused():
+        return true, te.cycle(ectx, 1)
+    }
+
+    return false, nil
+}
+
+// Routed events reach the tabs element directly or by bubbling up from the
+// active tab. Other events are only forwarded to the active tab.
+func (te *TabsElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    switch tev := ev.(type) {
+    case *MouseEvent:
+        x, y := tev.Position()
+        if tev.Action() != MOUSE_PRESS || y != 0 || ectx.EventHandled() {
+            return nil
+        }
+
+        ectx.MarkHandled()
+
+        index := te.tabAt(x)
+        if index != -1 {
+            err := te.SetActive(ectx, index)
+            if err != nil {
+                return err
+            }
+        }
+
+        if !ectx.FocusWithin() {
+            return ectx.Focus()
+        }
+
+        return nil
+
+    case *tcell.EventKey:
+        if ectx.EventHandled() {
+            return nil
+        }
+
+        handled, err := te.handleKey(ectx, tev)
+        if handled {
+            ectx.MarkHandled()
+        }
+
+        return err
+    }
+
+    if IsRoutedEvent(ev) || te.active == -1 {
+        return nil
+    }
+
+    cctx, _ := ectx.Child(te.active)
+    return cctx.ForwardEvent(ev)
+}
+
+// The active tab draws itself, this just draws the strip.
+func (te *TabsElement) Draw(cv *Canvas) {
+    cv.FillRect(0, 0, 1, cv.Cols(), ' ', te.style)
+
+    // Without tabs, the whole element is blank.
+    if te.active == -1 {
+        cv.Fill(' ', te.style)
+    }
+
+    off := te.stripOffset()
+
+    for i := 0; i + 1 < len(te.titleCols); i++ {
+        start := te.titleCols[i] - off
+        end := te.titleCols[i + 1] - off
+
+        style := te.style
+        if i == te.active {
+            style = te.activeStyle
+        }
+
+        // Each title is padded by a space on both sides.
+        cv.FillRect(0, start, 1, end - start - 1, ' ', style)
+
+        cv.Sub(0, start, 1, end - start - 2).PrintStyled(0, 1, te.titles[i], style)
+
+        cv.SetCellAt(0, end - 1, tabSeparator, te.style)
+    }
+}
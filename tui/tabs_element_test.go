@@ -0,0 +1,120 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Counts the ticks it receives and the times it is drawn.
+// Every tick asks for a draw.
+type tickCounter struct {
+    *tui.DefaultElement
+
+    ticks int
+    draws int
+}
+
+func newTickCounter() *tickCounter {
+    return &tickCounter{DefaultElement: tui.NewDefaultElement()}
+}
+
+func (tc *tickCounter) HandleEvent(ectx *tui.ElementContext, ev tcell.Event) error {
+    if _, ok := ev.(*tui.UpdateTickEvent); ok {
+        tc.ticks++
+        ectx.SetDrawFlag()
+    }
+
+    return nil
+}
+
+func (tc *tickCounter) Draw(cv *tui.Canvas) {
+    tc.draws++
+}
+
+func (tc *tickCounter) F() tui.ElementFactory {
+    return func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(tc)
+    }
+}
+
+func TestTabsHiddenTabsIdle(t *testing.T) {
+    first, second := newTickCounter(), newTickCounter()
+
+    h := tuitest.New(t, 4, 20, tui.TabsElementF(plain, blue,
+        tui.NewTab("One", first.F()),
+        tui.NewTab("Two", second.F())))
+
+    h.Tick(3)
+
+    if first.ticks != 3 || first.draws == 0 {
+        t.Errorf("expected the active tab ticked 3 times and drawn, got %d ticks and %d draws",
+            first.ticks, first.draws)
+    }
+
+    if second.ticks != 0 || second.draws != 0 {
+        t.Errorf("expected the hidden tab idle, got %d ticks and %d draws",
+            second.ticks, second.draws)
+    }
+
+    // Switching tabs swaps which one is idle.
+    h.Key(tcell.KeyPgDn, tcell.ModCtrl)
+    ticks, draws := first.ticks, first.draws
+
+    h.Tick(3)
+
+    if first.ticks != ticks || first.draws != draws {
+        t.Errorf("expected the hidden tab idle, got %d ticks and %d draws",
+            first.ticks - ticks, first.draws - draws)
+    }
+
+    if second.ticks != 3 || second.draws == 0 {
+        t.Errorf("expected the active tab ticked 3 times and drawn, got %d ticks and %d draws",
+            second.ticks, second.draws)
+    }
+}
+
+// Any container's hidden children are idle, not just the tabs'.
+func TestHiddenChildIdle(t *testing.T) {
+    shown, hidden := newTickCounter(), newTickCounter()
+
+    // hidden sits inside the hidden division.
+    var did tui.ElementID
+    h := tuitest.New(t, 2, 20, tui.DividedElementF(false, false, plain,
+        tui.FlexDivision(1, shown.F()),
+        tui.FlexDivision(1, func (env *tui.Environment) (tui.ElementID, error) {
+            var err error
+            did, err = env.CreateAndRegister(tui.DividedElementF(false, false, plain,
+                tui.FlexDivision(1, hidden.F())))
+
+            return did, err
+        })))
+
+    err := h.Env().SetHidden(did, true)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    h.Tick(3)
+
+    if shown.ticks != 3 {
+        t.Errorf("expected the shown child ticked 3 times, got %d", shown.ticks)
+    }
+
+    if hidden.ticks != 0 {
+        t.Errorf("expected the hidden child idle, got %d ticks", hidden.ticks)
+    }
+
+    err = h.Env().SetHidden(did, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    h.Tick(2)
+
+    if hidden.ticks != 2 {
+        t.Errorf("expected the unhidden child ticked 2 times, got %d", hidden.ticks)
+    }
+}
@@ -0,0 +1,396 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// -------------------------------------- Text Layout --------------------------------------
+
+// Text is broken into glyphs, one per grapheme cluster. A glyph takes up
+// one cell, which may be one or two columns wide. (East Asian wide
+// characters and most emoji take two)
+//
+// A text's layout is decided by a TextFormat.
+
+type WrapMode int
+
+const (
+    // Lines only break at newlines.
+    WRAP_NONE WrapMode = iota
+
+    // Lines break at any glyph.
+    WRAP_CHAR
+
+    // Lines break between words. Words too long for a line are broken
+    // at any glyph.
+    WRAP_WORD
+)
+
+type HAlign int
+
+const (
+    ALIGN_LEFT HAlign = iota
+    ALIGN_CENTER
+    ALIGN_RIGHT
+)
+
+type VAlign int
+
+const (
+    ALIGN_TOP VAlign = iota
+    ALIGN_MIDDLE
+    ALIGN_BOTTOM
+)
+
+const ellipsis = '…'
+
+type TextFormat struct {
+    wrap WrapMode
+
+    hAlign HAlign
+    vAlign VAlign
+
+    // If true, text which does not fit is cut off with an ellipsis.
+    ellipsis bool
+
+    // Tabs are expanded to spaces up to the next multiple of tabWidth.
+    tabWidth int
+}
+
+// By default, text is word wrapped, aligned top left, has no ellipsis and
+// has a tab width of 4.
+func NewTextFormat() TextFormat {
+    return TextFormat{
+        wrap: WRAP_WORD,
+        hAlign: ALIGN_LEFT,
+        vAlign: ALIGN_TOP,
+        ellipsis: false,
+        tabWidth: 4,
+    }
+}
+
+func (tf TextFormat) WithWrap(w WrapMode) TextFormat {
+    tf.wrap = w
+    return tf
+}
+
+func (tf TextFormat) WithAlign(h HAlign, v VAlign) TextFormat {
+    tf.hAlign = h
+    tf.vAlign = v
+    return tf
+}
+
+func (tf TextFormat) WithEllipsis() TextFormat {
+    tf.ellipsis = true
+    return tf
+}
+
+func (tf TextFormat) WithTabWidth(tw int) TextFormat {
+    tf.tabWidth = max(tw, 1)
+    return tf
+}
+
+type glyph struct {
+    // The first rune is the primary rune, the rest are combining.
+    runes []rune
+    width int
+    style tcell.Style
+}
+
+func (g glyph) isSpace() bool {
+    return len(g.runes) == 1 && g.runes[0] == ' '
+}
+
+// A single line of laid out text.
+type textLine struct {
+    glyphs []glyph
+    width int
+}
+
+func (tl *textLine) add(g glyph) {
+    tl.glyphs = append(tl.glyphs, g)
+    tl.width += g.width
+}
+
+// Removes spaces from the end of the line.
+func (tl *textLine) trimRight() {
+    for len(tl.glyphs) > 0 && tl.glyphs[len(tl.glyphs) - 1].isSpace() {
+        tl.width -= tl.glyphs[len(tl.glyphs) - 1].width
+        tl.glyphs = tl.glyphs[:len(tl.glyphs) - 1]
+    }
+}
+
+// Breaks text into paragraphs of glyphs. If singleLine is true, newlines
+// are treated as spaces and there is always exactly one paragraph.
+//
+// Tabs are expanded, zero width glyphs (control characters) are dropped.
+func toGlyphs(text StyledText, tabWidth int, singleLine bool) [][]glyph {
+    paras := make([][]glyph, 0, 1)
+    glyphs := make([]glyph, 0, text.Len())
+    col := 0
+
+    space := func (style tcell.Style) glyph {
+        return glyph{runes: []rune{' '}, width: 1, style: style}
+    }
+
+    for _, span := range text {
+        gr := uniseg.NewGraphemes(span.Text)
+        for gr.Next() {
+            runes := gr.Runes()
+
+            switch {
+            case runes[0] == '\n' || (runes[0] == '\r' && len(runes) > 1):
+                if singleLine {
+                    glyphs = append(glyphs, space(span.Style))
+                    col++
+                } else {
+                    paras = append(paras, glyphs)
+                    glyphs = make([]glyph, 0)
+                    col = 0
+                }
+
+            case runes[0] == '\t':
+                for n := tabWidth - (col % tabWidth); n > 0; n-- {
+                    glyphs = append(glyphs, space(span.Style))
+                    col++
+                }
+
+            default:
+                w := gr.Width()
+                if w == 0 {
+                    continue
+                }
+
+                glyphs = append(glyphs, glyph{runes: runes, width: w, style: span.Style})
+                col += w
+            }
+        }
+    }
+
+    return append(paras, glyphs)
+}
+
+// Returns text as a single line, newlines are treated as spaces.
+func toLine(text StyledText, tabWidth int) textLine {
+    glyphs := toGlyphs(text, tabWidth, true)[0]
+    return textLine{glyphs: glyphs, width: glyphsWidth(glyphs)}
+}
+
+// Returns the total width of the given glyphs.
+func glyphsWidth(glyphs []glyph) int {
+    w := 0
+    for _, g := range glyphs {
+        w += g.width
+    }
+
+    return w
+}
+
+// Breaks text into lines no wider than width using the given format.
+// A glyph wider than width is given its own line.
+func layoutText(text StyledText, width int, tf TextFormat) []textLine {
+    lines := make([]textLine, 0)
+
+    for _, glyphs := range toGlyphs(text, tf.tabWidth, false) {
+        switch tf.wrap {
+        case WRAP_NONE:
+            lines = append(lines, textLine{glyphs: glyphs, width: glyphsWidth(glyphs)})
+        case WRAP_CHAR:
+            lines = wrapChars(lines, glyphs, width)
+        default:
+            lines = wrapWords(lines, glyphs, width)
+        }
+    }
+
+    return lines
+}
+
+func wrapChars(lines []textLine, glyphs []glyph, width int) []textLine {
+    line := textLine{}
+
+    for _, g := range glyphs {
+        if line.width + g.width > width && len(line.glyphs) > 0 {
+            lines = append(lines, line)
+            line = textLine{}
+        }
+
+        line.add(g)
+    }
+
+    return append(lines, line)
+}
+
+func wrapWords(lines []textLine, glyphs []glyph, width int) []textLine {
+    line := textLine{}
+
+    // Set after a line is broken, spaces at the start of the
+    // following line are dropped.
+    wrapped := false
+
+    for i := 0; i < len(glyphs); {
+        // Find the next word (or run of spaces).
+        j := i + 1
+        for ; j < len(glyphs) && glyphs[j].isSpace() == glyphs[i].isSpace(); j++ {
+        }
+
+        word := glyphs[i:j]
+        i = j
+
+        if word[0].isSpace() {
+            if wrapped && len(line.glyphs) == 0 {
+                continue
+            }
+
+            // Spaces never cause a break, they are trimmed if a
+            // break follows them.
+            for _, g := range word {
+                line.add(g)
+            }
+
+            continue
+        }
+
+        wordWidth := glyphsWidth(word)
+
+        if line.width + wordWidth > width && len(line.glyphs) > 0 {
+            line.trimRight()
+            lines = append(lines, line)
+            line = textLine{}
+            wrapped = true
+        }
+
+        if wordWidth <= width {
+            for _, g := range word {
+                line.add(g)
+            }
+
+            continue
+        }
+
+        // The word is too long for any line, so it is broken up.
+        for _, g := range word {
+            if line.width + g.width > width && len(line.glyphs) > 0 {
+                lines = append(lines, line)
+                line = textLine{}
+                wrapped = true
+            }
+
+            line.add(g)
+        }
+    }
+
+    if wrapped || line.width > width {
+        line.trimRight()
+    }
+
+    return append(lines, line)
+}
+
+// Returns the line cut down to the given width.
+// If force is true or the line is too wide, the line ends in an ellipsis.
+func truncateLine(line textLine, width int, force bool) textLine {
+    if !force && line.width <= width {
+        return line
+    }
+
+    if width <= 0 {
+        return textLine{}
+    }
+
+    cut := textLine{}
+
+    // The ellipsis takes the style of the first glyph it replaces.
+    var style tcell.Style
+    if len(line.glyphs) > 0 {
+        style = line.glyphs[len(line.glyphs) - 1].style
+    }
+
+    for _, g := range line.glyphs {
+        if cut.width + g.width > width - 1 {
+            style = g.style
+            break
+        }
+
+        cut.add(g)
+    }
+
+    cut.add(glyph{runes: []rune{ellipsis}, width: 1, style: style})
+    return cut
+}
+
+// Returns the offset of something of the given size inside of
+// the given space.
+func alignOffset(space, size int, center, end bool) int {
+    switch {
+    case end:
+        return space - size
+    case center:
+        return (space - size) / 2
+    default:
+        return 0
+    }
+}
+
+// Draws the given line on row r of the canvas. Glyphs which would be
+// cut off by the edge of the canvas are not drawn.
+func drawLine(cv *Canvas, r int, c int, line textLine) {
+    for _, g := range line.glyphs {
+        if c >= 0 && c + g.width <= cv.Cols() {
+            cv.SetContentAt(r, c, g.runes[0], g.runes[1:], g.style)
+        }
+
+        c += g.width
+    }
+}
+
+// Draws text on the canvas according to the given format.
+// The canvas is not cleared first.
+func drawText(cv *Canvas, text StyledText, tf TextFormat) {
+    if cv.Cols() == 0 || cv.Rows() == 0 {
+        return
+    }
+
+    lines := layoutText(text, cv.Cols(), tf)
+
+    // Rows hidden because there is not enough room.
+    hidden := len(lines) - cv.Rows()
+    if hidden > 0 {
+        lines = lines[:cv.Rows()]
+    }
+
+    top := alignOffset(cv.Rows(), len(lines),
+        tf.vAlign == ALIGN_MIDDLE, tf.vAlign == ALIGN_BOTTOM)
+
+    for i, line := range lines {
+        if tf.ellipsis {
+            line = truncateLine(line, cv.Cols(), hidden > 0 && i == len(lines) - 1)
+        }
+
+        left := alignOffset(cv.Cols(), min(line.width, cv.Cols()),
+            tf.hAlign == ALIGN_CENTER, tf.hAlign == ALIGN_RIGHT)
+
+        drawLine(cv, top + i, left, line)
+    }
+}
+
+// Returns the size text would like to be given the available columns.
+func measureText(text StyledText, maxCols int, tf TextFormat) (int, int) {
+    if text.Len() == 0 || maxCols == 0 {
+        return 0, 0
+    }
+
+    // With unlimited room, lines never need to wrap.
+    if maxCols == UNBOUNDED {
+        tf.wrap = WRAP_NONE
+    }
+
+    lines := layoutText(text, maxCols, tf)
+
+    cols := 0
+    for _, line := range lines {
+        cols = max(cols, line.width)
+    }
+
+    return len(lines), cols
+}
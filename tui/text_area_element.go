@@ -0,0 +1,1143 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Text Area Element --------------------------------------
+
+// A text area is a multi-line text editor. Lines are soft wrapped to the
+// width of the text area unless wrapping is turned off, in which case the
+// view scrolls horizontally.
+//
+// The text itself is drawn by a private child which is as large as the
+// laid out text. The text area scrolls by moving the child's viewport.
+// (Like a ScrollElement)
+//
+// Keys:
+//      Arrows                      Move the caret. (Ctrl or Alt jumps words)
+//      PageUp/PageDown             Move the caret by a page.
+//      Home/End, Ctrl-A/E          Move to the start/end of the line.
+//      Ctrl-Home/Ctrl-End          Move to the start/end of the text.
+//      Shift + movement            Select.
+//      Backspace/Delete            Delete. (Ctrl or Alt deletes words)
+//      Ctrl-K                      Delete to the end of the line.
+//      Ctrl-Z/Ctrl-Y               Undo/Redo.
+//
+// Tab is left unhandled so focus can still move out of the text area.
+// (Pasted tabs are inserted)
+//
+// Tabs are kept in the text as is. Each one is displayed as spaces up to the
+// next tab stop of its visual row.
+
+const defaultUndoLimit = 1000
+
+// Lines scrolled per mouse wheel step.
+const wheelRows = 3
+
+// Columns between tab stops.
+const textAreaTabWidth = 4
+
+type TextAreaOptions struct {
+    wrap bool
+    lineNumbers bool
+
+    lineNumberStyle *tcell.Style
+    selectionStyle *tcell.Style
+
+    // The most edits which can be undone.
+    undoLimit int
+
+    onChange func (*ElementContext, string) error
+}
+
+// By default, a text area soft wraps, has no line numbers and can undo
+// the last 1000 edits.
+func NewTextAreaOptions() TextAreaOptions {
+    return TextAreaOptions{
+        wrap: true,
+        lineNumbers: false,
+        lineNumberStyle: nil,
+        selectionStyle: nil,
+        undoLimit: defaultUndoLimit,
+        onChange: nil,
+    }
+}
+
+func (to TextAreaOptions) WithoutWrap() TextAreaOptions {
+    to.wrap = false
+    return to
+}
+
+func (to TextAreaOptions) WithLineNumbers() TextAreaOptions {
+    to.lineNumbers = true
+    return to
+}
+
+// By default, line numbers are drawn dimmed.
+func (to TextAreaOptions) WithLineNumberStyle(s tcell.Style) TextAreaOptions {
+    to.lineNumberStyle = &s
+    return to
+}
+
+// By default, selected text is drawn reversed.
+func (to TextAreaOptions) WithSelectionStyle(s tcell.Style) TextAreaOptions {
+    to.selectionStyle = &s
+    return to
+}
+
+func (to TextAreaOptions) WithUndoLimit(l int) TextAreaOptions {
+    to.undoLimit = max(l, 0)
+    return to
+}
+
+// Called with the text area's text every time the user changes it.
+// (Including undo and redo)
+func (to TextAreaOptions) OnChange(fn func (*ElementContext, string) error) TextAreaOptions {
+    to.onChange = fn
+    return to
+}
+
+// A position in the text. col is an index into the glyphs of the line.
+type textPos struct {
+    line, col int
+}
+
+func (p textPos) before(q textPos) bool {
+    return p.line < q.line || (p.line == q.line && p.col < q.col)
+}
+
+// A single row on screen. Holds glyphs [start, end) of the given line.
+type visualRow struct {
+    line int
+    start, end int
+}
+
+// An edit replaces removed (starting at at) with inserted.
+type textEdit struct {
+    at textPos
+    removed string
+    inserted string
+
+    caretBefore textPos
+    caretAfter textPos
+}
+
+type TextAreaElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    lineNumberStyle tcell.Style
+    selectionStyle tcell.Style
+
+    opts TextAreaOptions
+
+    // Always at least one line.
+    lines [][]glyph
+
+    caret textPos
+
+    // The other end of the selection. Only valid when selecting is true.
+    anchor textPos
+    selecting bool
+
+    // The column vertical movement tries to stay in, -1 if not set.
+    goalCol int
+
+    // The layout of lines into visual rows.
+    rows []visualRow
+
+    // The first visual row of each line.
+    lineRows []int
+
+    // Width of the line number gutter. (0 without line numbers)
+    gutter int
+
+    // Size of the visible text. (Excludes the gutter)
+    viewRows, viewCols int
+
+    // Size of the child.
+    contentRows, contentCols int
+
+    // Offset of the visible text into the child.
+    rowOff, colOff int
+
+    focused bool
+
+    // Set between a paste start and paste end event.
+    pasting bool
+
+    undos []textEdit
+    redos []textEdit
+
+    // If true, the next edit can be merged into the last undo.
+    // (So undo removes words rather than single characters)
+    merging bool
+}
+
+// NOTE: A text area needs its child to draw the text, use TextAreaElementF.
+func NewTextAreaElement(s tcell.Style, opts TextAreaOptions) *TextAreaElement {
+    ls := s.Dim(true)
+    if opts.lineNumberStyle != nil {
+        ls = *opts.lineNumberStyle
+    }
+
+    ss := s.Reverse(true)
+    if opts.selectionStyle != nil {
+        ss = *opts.selectionStyle
+    }
+
+    return &TextAreaElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        lineNumberStyle: ls,
+        selectionStyle: ss,
+        opts: opts,
+        lines: [][]glyph{{}},
+        caret: textPos{},
+        anchor: textPos{},
+        selecting: false,
+        goalCol: -1,
+        rows: []visualRow{{line: 0, start: 0, end: 0}},
+        lineRows: []int{0},
+        gutter: 0,
+        viewRows: 0,
+        viewCols: 0,
+        contentRows: 0,
+        contentCols: 0,
+        rowOff: 0,
+        colOff: 0,
+        focused: false,
+        pasting: false,
+        undos: make([]textEdit, 0),
+        redos: make([]textEdit, 0),
+        merging: false,
+    }
+}
+
+func TextAreaElementF(s tcell.Style, opts TextAreaOptions) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        ta := NewTextAreaElement(s, opts)
+
+        bid, err := env.Register(&textAreaBody{
+            DefaultElement: NewDefaultElement(),
+            ta: ta,
+        })
+        if err != nil {
+            return -1, err
+        }
+
+        eid, err := env.Register(ta)
+        if err != nil {
+            // This will never error.
+            env.Deregister(bid)
+            return -1, err
+        }
+
+        // This will never error.
+        env.Attach(eid, bid)
+        return eid, nil
+    }
+}
+
+func (ta *TextAreaElement) AcceptsFocus() bool {
+    return true
+}
+
+func (ta *TextAreaElement) GetText() string {
+    return ta.textBetween(textPos{}, ta.end())
+}
+
+// Replaces the text, moves the caret to the start and clears the undo
+// history. Line endings are stored as "\n".
+// NOTE: This does not call the change callback.
+func (ta *TextAreaElement) SetText(ectx *ElementContext, t string) error {
+    ta.lines = toTextAreaLines(t)
+    ta.caret = textPos{}
+    ta.selecting = false
+    ta.goalCol = -1
+
+    ta.undos = ta.undos[:0]
+    ta.redos = ta.redos[:0]
+    ta.merging = false
+
+    ta.rowOff = 0
+    ta.colOff = 0
+
+    return ta.refresh(ectx)
+}
+
+// Returns the selected text, or "" if nothing is selected.
+func (ta *TextAreaElement) GetSelectedText() string {
+    lo, hi, ok := ta.selection()
+    if !ok {
+        return ""
+    }
+
+    return ta.textBetween(lo, hi)
+}
+
+// Splits text into lines of glyphs. Unlike toGlyphs, tabs are kept as
+// single glyphs. (See tabGlyph)
+func toTextAreaLines(t string) [][]glyph {
+    lines := [][]glyph{{}}
+
+    for i, part := range strings.Split(t, "\t") {
+        last := len(lines) - 1
+        if i > 0 {
+            lines[last] = append(lines[last], tabGlyph)
+        }
+
+        // part has no tabs, so the tab width is never used.
+        paras := toGlyphs(PlainText(part, tcell.StyleDefault), 1, false)
+
+        lines[last] = append(lines[last], paras[0]...)
+        lines = append(lines, paras[1:]...)
+    }
+
+    return lines
+}
+
+// A tab's width field is unused, its width depends on where it is
+// displayed. (See glyphWidthAt)
+var tabGlyph = glyph{runes: []rune{'\t'}, width: 1, style: tcell.StyleDefault}
+
+func isTab(g glyph) bool {
+    return g.runes[0] == '\t'
+}
+
+// Tabs separate words like spaces do.
+func isBlank(g glyph) bool {
+    return g.isSpace() || isTab(g)
+}
+
+// The width of g when displayed at the given column of a visual row.
+func glyphWidthAt(g glyph, col int) int {
+    if isTab(g) {
+        return textAreaTabWidth - col % textAreaTabWidth
+    }
+
+    return g.width
+}
+
+// The width of the given glyphs when displayed at the start of a visual row.
+func rowWidth(glyphs []glyph) int {
+    w := 0
+    for _, g := range glyphs {
+        w += glyphWidthAt(g, w)
+    }
+
+    return w
+}
+
+// The position after the last glyph.
+func (ta *TextAreaElement) end() textPos {
+    last := len(ta.lines) - 1
+    return textPos{line: last, col: len(ta.lines[last])}
+}
+
+func (ta *TextAreaElement) textBetween(lo, hi textPos) string {
+    var sb strings.Builder
+
+    for l := lo.line; l <= hi.line; l++ {
+        line := ta.lines[l]
+
+        start, end := 0, len(line)
+        if l == lo.line {
+            start = lo.col
+        }
+
+        if l == hi.line {
+            end = hi.col
+        }
+
+        for _, g := range line[start:end] {
+            sb.WriteString(string(g.runes))
+        }
+
+        if l != hi.line {
+            sb.WriteByte('\n')
+        }
+    }
+
+    return sb.String()
+}
+
+// Replaces the text between lo and hi with t.
+// Returns the position after the inserted text.
+func (ta *TextAreaElement) replace(lo, hi textPos, t string) textPos {
+    head := ta.lines[lo.line][:lo.col]
+    tail := ta.lines[hi.line][hi.col:]
+
+    added := toTextAreaLines(t)
+
+    first := make([]glyph, 0, len(head) + len(added[0]))
+    first = append(append(first, head...), added[0]...)
+    added[0] = first
+
+    last := len(added) - 1
+    endPos := textPos{line: lo.line + last, col: len(added[last])}
+
+    added[last] = append(added[last], tail...)
+
+    lines := make([][]glyph, 0, len(ta.lines) - (hi.line - lo.line) + last)
+    lines = append(lines, ta.lines[:lo.line]...)
+    lines = append(lines, added...)
+    lines = append(lines, ta.lines[hi.line + 1:]...)
+
+    ta.lines = lines
+    return endPos
+}
+
+// The position after the given text if it were inserted at p.
+func textEnd(p textPos, t string) textPos {
+    paras := toTextAreaLines(t)
+    if len(paras) == 1 {
+        return textPos{line: p.line, col: p.col + len(paras[0])}
+    }
+
+    return textPos{line: p.line + len(paras) - 1, col: len(paras[len(paras) - 1])}
+}
+
+// Replaces the text between lo and hi with t, recording the edit so it can
+// be undone. If merge is true, the edit may be merged with the last one.
+func (ta *TextAreaElement) edit(ectx *ElementContext, lo, hi textPos, t string, merge bool) error {
+    removed := ta.textBetween(lo, hi)
+    if removed == "" && t == "" {
+        return nil
+    }
+
+    before := ta.caret
+    after := ta.replace(lo, hi, t)
+
+    ta.caret = after
+    ta.selecting = false
+    ta.goalCol = -1
+
+    ta.redos = ta.redos[:0]
+
+    if !(merge && ta.merging && ta.mergeEdit(lo, removed, t, after)) {
+        ta.undos = append(ta.undos, textEdit{
+            at: lo,
+            removed: removed,
+            inserted: t,
+            caretBefore: before,
+            caretAfter: after,
+        })
+
+        if len(ta.undos) > ta.opts.undoLimit {
+            ta.undos = ta.undos[len(ta.undos) - ta.opts.undoLimit:]
+        }
+    }
+
+    ta.merging = merge
+
+    return ta.changed(ectx)
+}
+
+// Tries to merge an edit into the last undo.
+// Only runs of insertions or runs of deletions are merged.
+func (ta *TextAreaElement) mergeEdit(at textPos, removed, inserted string, after textPos) bool {
+    if len(ta.undos) == 0 {
+        return false
+    }
+
+    last := &ta.undos[len(ta.undos) - 1]
+
+    switch {
+    // Typing after the last insertion.
+    case removed == "" && last.removed == "" && at == last.caretAfter:
+        last.inserted += inserted
+
+    // Backspacing before the last deletion.
+    case inserted == "" && last.inserted == "" && textEnd(at, removed) == last.at:
+        last.at = at
+        last.removed = removed + last.removed
+
+    // Deleting after the last deletion.
+    case inserted == "" && last.inserted == "" && at == last.at:
+        last.removed += removed
+
+    default:
+        return false
+    }
+
+    last.caretAfter = after
+    return true
+}
+
+func (ta *TextAreaElement) undo(ectx *ElementContext) error {
+    if len(ta.undos) == 0 {
+        return nil
+    }
+
+    e := ta.undos[len(ta.undos) - 1]
+    ta.undos = ta.undos[:len(ta.undos) - 1]
+
+    ta.replace(e.at, textEnd(e.at, e.inserted), e.removed)
+    ta.caret = e.caretBefore
+    ta.redos = append(ta.redos, e)
+
+    return ta.afterHistory(ectx)
+}
+
+func (ta *TextAreaElement) redo(ectx *ElementContext) error {
+    if len(ta.redos) == 0 {
+        return nil
+    }
+
+    e := ta.redos[len(ta.redos) - 1]
+    ta.redos = ta.redos[:len(ta.redos) - 1]
+
+    ta.replace(e.at, textEnd(e.at, e.removed), e.inserted)
+    ta.caret = e.caretAfter
+    ta.undos = append(ta.undos, e)
+
+    return ta.afterHistory(ectx)
+}
+
+func (ta *TextAreaElement) afterHistory(ectx *ElementContext) error {
+    ta.selecting = false
+    ta.goalCol = -1
+    ta.merging = false
+
+    return ta.changed(ectx)
+}
+
+// Called after the user changes the text.
+func (ta *TextAreaElement) changed(ectx *ElementContext) error {
+    err := ta.refresh(ectx)
+    if err != nil {
+        return err
+    }
+
+    if ta.opts.onChange != nil {
+        return ta.opts.onChange(ectx, ta.GetText())
+    }
+
+    return nil
+}
+
+// Returns the selected range. ok is false if nothing is selected.
+func (ta *TextAreaElement) selection() (textPos, textPos, bool) {
+    if !ta.selecting || ta.anchor == ta.caret {
+        return textPos{}, textPos{}, false
+    }
+
+    if ta.anchor.before(ta.caret) {
+        return ta.anchor, ta.caret, true
+    }
+
+    return ta.caret, ta.anchor, true
+}
+
+func (ta *TextAreaElement) inSelection(p textPos) bool {
+    lo, hi, ok := ta.selection()
+    return ok && !p.before(lo) && p.before(hi)
+}
+
+// Moves the caret. If extend is true, the selection is extended.
+func (ta *TextAreaElement) moveTo(p textPos, extend bool) {
+    if extend {
+        if !ta.selecting {
+            ta.selecting = true
+            ta.anchor = ta.caret
+        }
+    } else {
+        ta.selecting = false
+    }
+
+    ta.caret = p
+    ta.merging = false
+}
+
+// The position one glyph before p. (Or p at the start of the text)
+func (ta *TextAreaElement) prev(p textPos) textPos {
+    switch {
+    case p.col > 0:
+        return textPos{line: p.line, col: p.col - 1}
+    case p.line > 0:
+        return textPos{line: p.line - 1, col: len(ta.lines[p.line - 1])}
+    default:
+        return p
+    }
+}
+
+// The position one glyph after p. (Or p at the end of the text)
+func (ta *TextAreaElement) next(p textPos) textPos {
+    switch {
+    case p.col < len(ta.lines[p.line]):
+        return textPos{line: p.line, col: p.col + 1}
+    case p.line < len(ta.lines) - 1:
+        return textPos{line: p.line + 1, col: 0}
+    default:
+        return p
+    }
+}
+
+// The start of the word before p. At the start of a line, this is the end
+// of the line above.
+func (ta *TextAreaElement) wordLeft(p textPos) textPos {
+    if p.col == 0 {
+        return ta.prev(p)
+    }
+
+    line := ta.lines[p.line]
+
+    i := p.col
+    for i > 0 && isBlank(line[i-1]) {
+        i--
+    }
+
+    for i > 0 && !isBlank(line[i-1]) {
+        i--
+    }
+
+    return textPos{line: p.line, col: i}
+}
+
+// The end of the word after p. At the end of a line, this is the start
+// of the line below.
+func (ta *TextAreaElement) wordRight(p textPos) textPos {
+    line := ta.lines[p.line]
+    if p.col == len(line) {
+        return ta.next(p)
+    }
+
+    i := p.col
+    for i < len(line) && isBlank(line[i]) {
+        i++
+    }
+
+    for i < len(line) && !isBlank(line[i]) {
+        i++
+    }
+
+    return textPos{line: p.line, col: i}
+}
+
+// Lays out the lines into visual rows.
+func (ta *TextAreaElement) layout() {
+    ta.gutter = 0
+    if ta.opts.lineNumbers {
+        ta.gutter = len(strconv.Itoa(len(ta.lines))) + 1
+    }
+
+    ta.viewRows = ta.GetRows()
+    ta.viewCols = max(ta.GetCols() - ta.gutter, 0)
+
+    ta.rows = ta.rows[:0]
+    ta.lineRows = ta.lineRows[:0]
+
+    widest := 0
+
+    for i, line := range ta.lines {
+        ta.lineRows = append(ta.lineRows, len(ta.rows))
+        widest = max(widest, rowWidth(line))
+
+        if !ta.opts.wrap || ta.viewCols == 0 {
+            ta.rows = append(ta.rows, visualRow{line: i, start: 0, end: len(line)})
+            continue
+        }
+
+        ta.rows = wrapRow(ta.rows, i, line, ta.viewCols)
+    }
+
+    ta.contentRows = min(max(len(ta.rows), ta.viewRows), maxScrollDim)
+    ta.contentCols = ta.viewCols
+
+    // Leave room for the caret after the widest line.
+    if !ta.opts.wrap {
+        ta.contentCols = min(max(widest + 1, ta.viewCols), maxScrollDim)
+    }
+}
+
+// Adds the visual rows of the given line. Rows break after the last space
+// which fits, or at any glyph if there is no such space.
+func wrapRow(rows []visualRow, l int, line []glyph, width int) []visualRow {
+    start := 0
+
+    for {
+        w := 0
+        i := start
+        for ; i < len(line) && w + glyphWidthAt(line[i], w) <= width; i++ {
+            w += glyphWidthAt(line[i], w)
+        }
+
+        if i == len(line) {
+            return append(rows, visualRow{line: l, start: start, end: i})
+        }
+
+        // A glyph wider than the whole row gets a row to itself.
+        end := max(i, start + 1)
+
+        for j := i - 1; j > start; j-- {
+            if isBlank(line[j]) {
+                end = j + 1
+                break
+            }
+        }
+
+        rows = append(rows, visualRow{line: l, start: start, end: end})
+        start = end
+    }
+}
+
+// The visual row the given position is displayed on.
+func (ta *TextAreaElement) rowOf(p textPos) int {
+    first := ta.lineRows[p.line]
+
+    last := len(ta.rows) - 1
+    if p.line + 1 < len(ta.lineRows) {
+        last = ta.lineRows[p.line + 1] - 1
+    }
+
+    for r := last; r > first; r-- {
+        if ta.rows[r].start <= p.col {
+            return r
+        }
+    }
+
+    return first
+}
+
+// The column the given position is displayed at.
+func (ta *TextAreaElement) colOf(p textPos) int {
+    vr := ta.rows[ta.rowOf(p)]
+    return rowWidth(ta.lines[p.line][vr.start:p.col])
+}
+
+// The position nearest to the given column of the given visual row.
+func (ta *TextAreaElement) posAt(row int, col int) textPos {
+    row = max(min(row, len(ta.rows) - 1), 0)
+    vr := ta.rows[row]
+    line := ta.lines[vr.line]
+
+    // The end of a wrapped row is displayed at the start of the next row.
+    end := vr.end
+    if end < len(line) {
+        end--
+    }
+
+    c := 0
+    for i := vr.start; i < end; i++ {
+        w := glyphWidthAt(line[i], c)
+        if col < c + (w + 1) / 2 {
+            return textPos{line: vr.line, col: i}
+        }
+
+        c += w
+    }
+
+    return textPos{line: vr.line, col: max(end, vr.start)}
+}
+
+// Moves the caret by the given number of visual rows.
+func (ta *TextAreaElement) moveRows(d int, extend bool) {
+    if ta.goalCol == -1 {
+        ta.goalCol = ta.colOf(ta.caret)
+    }
+
+    goal := ta.goalCol
+    ta.moveTo(ta.posAt(ta.rowOf(ta.caret) + d, goal), extend)
+    ta.goalCol = goal
+}
+
+// Keeps the offsets within the content.
+func (ta *TextAreaElement) clampOffsets() {
+    ta.rowOff = max(min(ta.rowOff, ta.contentRows - ta.viewRows), 0)
+    ta.colOff = max(min(ta.colOff, ta.contentCols - ta.viewCols), 0)
+}
+
+// Scrolls so that the caret is visible.
+func (ta *TextAreaElement) scrollToCaret() {
+    row := ta.rowOf(ta.caret)
+
+    if row < ta.rowOff {
+        ta.rowOff = row
+    }
+
+    if ta.viewRows > 0 && row >= ta.rowOff + ta.viewRows {
+        ta.rowOff = row - ta.viewRows + 1
+    }
+
+    col := ta.colOf(ta.caret)
+
+    if col < ta.colOff {
+        ta.colOff = col
+    }
+
+    if ta.viewCols > 0 && col >= ta.colOff + ta.viewCols {
+        ta.colOff = col - ta.viewCols + 1
+    }
+
+    ta.clampOffsets()
+}
+
+// Lays out the text, scrolls to the caret and updates the child.
+func (ta *TextAreaElement) refresh(ectx *ElementContext) error {
+    ta.layout()
+    ta.scrollToCaret()
+
+    cctx, _ := ectx.Child(0)
+    err := cctx.ForwardResize(0, 0, ta.contentRows, ta.contentCols)
+    if err != nil {
+        return err
+    }
+
+    return ta.updateView(ectx)
+}
+
+// Updates the child's viewport and the cursor after scrolling.
+func (ta *TextAreaElement) updateView(ectx *ElementContext) error {
+    cctx, _ := ectx.Child(0)
+    err := cctx.SetViewport(NewViewport(ta.GetC() + ta.gutter, ta.GetR(),
+        ta.colOff, ta.rowOff, ta.viewCols, ta.viewRows))
+    if err != nil {
+        return err
+    }
+
+    if ta.focused {
+        ectx.ShowCursor(ta.rowOf(ta.caret) - ta.rowOff,
+            ta.gutter + ta.colOf(ta.caret) - ta.colOff)
+    }
+
+    // Line numbers must be redrawn.
+    ectx.SetDrawFlag()
+    return nil
+}
+
+// A text area would like to show every line, but can shrink to a
+// single cell.
+func (ta *TextAreaElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    widest := 0
+    for _, line := range ta.lines {
+        widest = max(widest, rowWidth(line))
+    }
+
+    gutter := 0
+    if ta.opts.lineNumbers {
+        gutter = len(strconv.Itoa(len(ta.lines))) + 1
+    }
+
+    return SizeHint{
+        PrefRows: len(ta.lines),
+        PrefCols: gutter + widest + 1,
+        MinRows: 1,
+        MinCols: gutter + 1,
+        MaxRows: UNBOUNDED,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (ta *TextAreaElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := ta.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    return ta.refresh(ectx)
+}
+
+// Deletes the selection, or the text between the caret and p if nothing
+// is selected.
+func (ta *TextAreaElement) deleteTo(ectx *ElementContext, p textPos) error {
+    lo, hi, ok := ta.selection()
+    if !ok {
+        lo, hi = ta.caret, p
+        if p.before(ta.caret) {
+            lo, hi = p, ta.caret
+        }
+    }
+
+    return ta.edit(ectx, lo, hi, "", !ok)
+}
+
+// Inserts text at the caret, replacing the selection.
+func (ta *TextAreaElement) insert(ectx *ElementContext, t string, merge bool) error {
+    lo, hi, ok := ta.selection()
+    if !ok {
+        lo, hi = ta.caret, ta.caret
+    }
+
+    return ta.edit(ectx, lo, hi, t, merge && !ok)
+}
+
+// Performs the action of the given key.
+// handled is false if the text area does not use the key.
+func (ta *TextAreaElement) handleKey(ectx *ElementContext, ev *tcell.EventKey) (bool, error) {
+    mods := ev.Modifiers()
+    shift := mods & tcell.ModShift != 0
+    ctrl := mods & tcell.ModCtrl != 0
+    word := mods & (tcell.ModCtrl | tcell.ModAlt) != 0
+
+    lo, hi, selected := ta.selection()
+
+    switch ev.Key() {
+    case tcell.KeyRune:
+        if mods & tcell.ModAlt != 0 {
+            return false, nil
+        }
+
+        // Undo removes whole words at a time.
+        return true, ta.insert(ectx, string(ev.Rune()), ta.pasting || ev.Rune() != ' ')
+
+    case tcell.KeyEnter:
+        return true, ta.insert(ectx, "\n", ta.pasting)
+
+    case tcell.KeyTab:
+        if !ta.pasting {
+            return false, nil
+        }
+
+        return true, ta.insert(ectx, "\t", true)
+
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if word {
+            return true, ta.deleteTo(ectx, ta.wordLeft(ta.caret))
+        }
+
+        return true, ta.deleteTo(ectx, ta.prev(ta.caret))
+
+    case tcell.KeyDelete:
+        if word {
+            return true, ta.deleteTo(ectx, ta.wordRight(ta.caret))
+        }
+
+        return true, ta.deleteTo(ectx, ta.next(ta.caret))
+
+    case tcell.KeyCtrlK:
+        eol := textPos{line: ta.caret.line, col: len(ta.lines[ta.caret.line])}
+        if eol == ta.caret {
+            eol = ta.next(eol)
+        }
+
+        return true, ta.deleteTo(ectx, eol)
+
+    case tcell.KeyCtrlZ:
+        return true, ta.undo(ectx)
+    case tcell.KeyCtrlY:
+        return true, ta.redo(ectx)
+
+    case tcell.KeyLeft:
+        switch {
+        case word:
+            ta.moveTo(ta.wordLeft(ta.caret), shift)
+        case selected && !shift:
+            ta.moveTo(lo, false)
+        default:
+            ta.moveTo(ta.prev(ta.caret), shift)
+        }
+
+        ta.goalCol = -1
+
+    case tcell.KeyRight:
+        switch {
+        case word:
+            ta.moveTo(ta.wordRight(ta.caret), shift)
+        case selected && !shift:
+            ta.moveTo(hi, false)
+        default:
+            ta.moveTo(ta.next(ta.caret), shift)
+        }
+
+        ta.goalCol = -1
+
+    case tcell.KeyUp:
+        ta.moveRows(-1, shift)
+    case tcell.KeyDown:
+        ta.moveRows(1, shift)
+    case tcell.KeyPgUp:
+        ta.moveRows(-max(ta.viewRows - 1, 1), shift)
+    case tcell.KeyPgDn:
+        ta.moveRows(max(ta.viewRows - 1, 1), shift)
+
+    case tcell.KeyHome, tcell.KeyCtrlA:
+        if ctrl && ev.Key() == tcell.KeyHome {
+            ta.moveTo(textPos{}, shift)
+        } else {
+            ta.moveTo(textPos{line: ta.caret.line, col: 0}, shift)
+        }
+
+        ta.goalCol = -1
+
+    case tcell.KeyEnd, tcell.KeyCtrlE:
+        if ctrl && ev.Key() == tcell.KeyEnd {
+            ta.moveTo(ta.end(), shift)
+        } else {
+            ta.moveTo(textPos{line: ta.caret.line, col: len(ta.lines[ta.caret.line])}, shift)
+        }
+
+        ta.goalCol = -1
+
+    default:
+        return false, nil
+    }
+
+    // Only movement gets here. The selection must be redrawn.
+    ta.scrollToCaret()
+
+    cctx, _ := ectx.Child(0)
+    cctx.SetDrawFlag()
+
+    return true, ta.updateView(ectx)
+}
+
+func (ta *TextAreaElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    switch tev := ev.(type) {
+    case *FocusEvent:
+        ta.focused = true
+        return ta.updateView(ectx)
+
+    case *BlurEvent:
+        ta.focused = false
+        ta.pasting = false
+        ectx.HideCursor()
+
+    case *tcell.EventPaste:
+        ta.pasting = tev.Start()
+        ta.merging = false
+        ectx.MarkHandled()
+
+    case *MouseEvent:
+        return ta.handleMouse(ectx, tev)
+
+    case *tcell.EventKey:
+        if ectx.EventHandled() {
+            return nil
+        }
+
+        handled, err := ta.handleKey(ectx, tev)
+        if handled {
+            ectx.MarkHandled()
+        }
+
+        return err
+    }
+
+    return nil
+}
+
+// Mouse events usually target the child and bubble up to here.
+func (ta *TextAreaElement) handleMouse(ectx *ElementContext, ev *MouseEvent) error {
+    if ectx.EventHandled() {
+        return nil
+    }
+
+    x, y := ev.Position()
+    p := ta.posAt(ta.rowOff + y, x - ta.gutter + ta.colOff)
+
+    switch ev.Action() {
+    case MOUSE_PRESS:
+        ectx.MarkHandled()
+
+        err := ectx.Focus()
+        if err != nil {
+            return err
+        }
+
+        ta.moveTo(p, ev.Modifiers() & tcell.ModShift != 0)
+
+    case MOUSE_DRAG:
+        ectx.MarkHandled()
+        ta.moveTo(p, true)
+
+    case MOUSE_WHEEL:
+        buttons := ev.Buttons()
+
+        switch {
+        case buttons & tcell.WheelUp != 0:
+            ta.rowOff -= wheelRows
+        case buttons & tcell.WheelDown != 0:
+            ta.rowOff += wheelRows
+        default:
+            return nil
+        }
+
+        // Scrolling does not move the caret.
+        ectx.MarkHandled()
+        ta.clampOffsets()
+
+        return ta.updateView(ectx)
+
+    default:
+        return nil
+    }
+
+    ta.goalCol = -1
+    ta.scrollToCaret()
+
+    cctx, _ := ectx.Child(0)
+    cctx.SetDrawFlag()
+
+    return ta.updateView(ectx)
+}
+
+// The child draws the text, this just draws the line numbers.
+func (ta *TextAreaElement) Draw(cv *Canvas) {
+    if ta.gutter == 0 {
+        return
+    }
+
+    cv.FillRect(0, 0, ta.viewRows, ta.gutter, ' ', ta.lineNumberStyle)
+
+    for i := 0; i < ta.viewRows && ta.rowOff + i < len(ta.rows); i++ {
+        vr := ta.rows[ta.rowOff + i]
+
+        // Only the first row of each line is numbered.
+        if vr.start != 0 {
+            continue
+        }
+
+        num := strconv.Itoa(vr.line + 1)
+        cv.PrintStyled(i, ta.gutter - 1 - len(num), num, ta.lineNumberStyle)
+    }
+}
+
+// The child of a text area. It is as large as the laid out text, but only
+// draws the rows which are visible.
+type textAreaBody struct {
+    *DefaultElement
+
+    ta *TextAreaElement
+}
+
+func (tb *textAreaBody) Draw(cv *Canvas) {
+    ta := tb.ta
+
+    cv.FillRect(ta.rowOff, ta.colOff, ta.viewRows, ta.viewCols, ' ', ta.style)
+
+    for r := ta.rowOff; r < ta.rowOff + ta.viewRows && r < len(ta.rows); r++ {
+        vr := ta.rows[r]
+        line := ta.lines[vr.line]
+
+        c := 0
+        for i := vr.start; i < vr.end; i++ {
+            g := line[i]
+
+            style := ta.style
+            if ta.inSelection(textPos{line: vr.line, col: i}) {
+                style = ta.selectionStyle
+            }
+
+            w := glyphWidthAt(g, c)
+
+            if isTab(g) {
+                cv.FillRect(r, c, 1, w, ' ', style)
+            } else if c + w <= cv.Cols() {
+                cv.SetContentAt(r, c, g.runes[0], g.runes[1:], style)
+            }
+
+            c += w
+        }
+
+        // A selected line break is shown as a selected space.
+        end := textPos{line: vr.line, col: vr.end}
+        if vr.end == len(line) && vr.line < len(ta.lines) - 1 && ta.inSelection(end) {
+            cv.SetCellAt(r, c, ' ', ta.selectionStyle)
+        }
+    }
+}
@@ -0,0 +1,287 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestMergeEdit(t *testing.T) {
+    pos := func (line, col int) textPos {
+        return textPos{line: line, col: col}
+    }
+
+    cases := []struct {
+        name string
+        last textEdit
+
+        at textPos
+        removed, inserted string
+        after textPos
+
+        merged bool
+        expected textEdit
+    }{
+        {"typing continues insertion",
+            textEdit{at: pos(0, 0), inserted: "ab", caretAfter: pos(0, 2)},
+            pos(0, 2), "", "c", pos(0, 3),
+            true, textEdit{at: pos(0, 0), inserted: "abc", caretAfter: pos(0, 3)}},
+        {"typing elsewhere",
+            textEdit{at: pos(0, 0), inserted: "ab", caretAfter: pos(0, 2)},
+            pos(0, 5), "", "c", pos(0, 6),
+            false, textEdit{at: pos(0, 0), inserted: "ab", caretAfter: pos(0, 2)}},
+        {"typing after newline",
+            textEdit{at: pos(0, 3), inserted: "\n", caretAfter: pos(1, 0)},
+            pos(1, 0), "", "x", pos(1, 1),
+            true, textEdit{at: pos(0, 3), inserted: "\nx", caretAfter: pos(1, 1)}},
+        {"backspace continues deletion",
+            textEdit{at: pos(0, 4), removed: "d", caretAfter: pos(0, 4)},
+            pos(0, 3), "c", "", pos(0, 3),
+            true, textEdit{at: pos(0, 3), removed: "cd", caretAfter: pos(0, 3)}},
+        {"backspace over newline",
+            textEdit{at: pos(1, 0), removed: "a", caretAfter: pos(1, 0)},
+            pos(0, 2), "\n", "", pos(0, 2),
+            true, textEdit{at: pos(0, 2), removed: "\na", caretAfter: pos(0, 2)}},
+        {"delete continues deletion",
+            textEdit{at: pos(0, 1), removed: "b", caretAfter: pos(0, 1)},
+            pos(0, 1), "c", "", pos(0, 1),
+            true, textEdit{at: pos(0, 1), removed: "bc", caretAfter: pos(0, 1)}},
+        {"deletion after insertion",
+            textEdit{at: pos(0, 0), inserted: "ab", caretAfter: pos(0, 2)},
+            pos(0, 1), "b", "", pos(0, 1),
+            false, textEdit{at: pos(0, 0), inserted: "ab", caretAfter: pos(0, 2)}},
+        {"insertion after deletion",
+            textEdit{at: pos(0, 1), removed: "b", caretAfter: pos(0, 1)},
+            pos(0, 1), "", "x", pos(0, 2),
+            false, textEdit{at: pos(0, 1), removed: "b", caretAfter: pos(0, 1)}},
+        {"replacement never merges",
+            textEdit{at: pos(0, 0), inserted: "ab", caretAfter: pos(0, 2)},
+            pos(0, 2), "c", "d", pos(0, 3),
+            false, textEdit{at: pos(0, 0), inserted: "ab", caretAfter: pos(0, 2)}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            ta := NewTextAreaElement(tcell.StyleDefault, NewTextAreaOptions())
+            ta.undos = append(ta.undos, tc.last)
+
+            merged := ta.mergeEdit(tc.at, tc.removed, tc.inserted, tc.after)
+            if merged != tc.merged {
+                t.Fatalf("expected merged=%t, got %t", tc.merged, merged)
+            }
+
+            if actual := ta.undos[len(ta.undos) - 1]; actual != tc.expected {
+                t.Errorf("expected %+v, got %+v", tc.expected, actual)
+            }
+        })
+    }
+
+    ta := NewTextAreaElement(tcell.StyleDefault, NewTextAreaOptions())
+    if ta.mergeEdit(textPos{}, "", "a", textPos{col: 1}) {
+        t.Error("merged without an undo")
+    }
+}
+
+// Returns a text area as the root of a new environment.
+func newTextAreaTestEnv(t *testing.T, opts TextAreaOptions) (*Environment, *TextAreaElement) {
+    s := tcell.NewSimulationScreen("UTF-8")
+    err := s.Init()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    t.Cleanup(s.Fini)
+    s.SetSize(20, 5)
+
+    env := NewEnvironment(s, UNLIMITED_CAPACITY, 10 * time.Millisecond)
+
+    eid, err := env.CreateAndRegister(TextAreaElementF(tcell.StyleDefault, opts))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    err = env.MakeRoot(eid)
+    if err == nil {
+        err = env.Focus(eid)
+    }
+
+    if err == nil {
+        err = env.Render()
+    }
+
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return env, env.entry(eid).e.(*TextAreaElement)
+}
+
+func TestTextAreaUndoRedo(t *testing.T) {
+    env, ta := newTextAreaTestEnv(t, NewTextAreaOptions())
+
+    key := func (k tcell.Key, r rune, mod tcell.ModMask) {
+        err := env.ProcessEvent(tcell.NewEventKey(k, r, mod))
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    typeText := func (str string) {
+        for _, r := range str {
+            key(tcell.KeyRune, r, tcell.ModNone)
+        }
+    }
+
+    expect := func (expected string) {
+        t.Helper()
+
+        if actual := ta.GetText(); actual != expected {
+            t.Fatalf("expected %q, got %q", expected, actual)
+        }
+    }
+
+    typeText("hello world")
+
+    // Words and the spaces between them are undone separately.
+    key(tcell.KeyCtrlZ, 0, tcell.ModCtrl)
+    expect("hello ")
+    key(tcell.KeyCtrlZ, 0, tcell.ModCtrl)
+    expect("hello")
+
+    key(tcell.KeyCtrlY, 0, tcell.ModCtrl)
+    expect("hello ")
+
+    // A new edit clears what could be redone.
+    typeText("x")
+    key(tcell.KeyCtrlY, 0, tcell.ModCtrl)
+    expect("hello x")
+
+    // Backspaces merge into one deletion.
+    key(tcell.KeyBackspace2, 0, tcell.ModNone)
+    key(tcell.KeyBackspace2, 0, tcell.ModNone)
+    key(tcell.KeyBackspace2, 0, tcell.ModNone)
+    expect("hell")
+
+    key(tcell.KeyCtrlZ, 0, tcell.ModCtrl)
+    expect("hello x")
+
+    // Undoing everything, and then some.
+    for i := 0; i < 10; i++ {
+        key(tcell.KeyCtrlZ, 0, tcell.ModCtrl)
+    }
+
+    expect("")
+}
+
+func TestTextAreaUndoLimit(t *testing.T) {
+    env, ta := newTextAreaTestEnv(t, NewTextAreaOptions().WithUndoLimit(2))
+
+    for _, r := range "a b c" {
+        err := env.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if len(ta.undos) != 2 {
+        t.Fatalf("expected 2 undos, got %d", len(ta.undos))
+    }
+}
+
+func TestTextAreaRoundTrip(t *testing.T) {
+    cases := []struct {
+        name string
+        text string
+        expected string
+    }{
+        {"plain", "hello", "hello"},
+        {"empty", "", ""},
+        {"tab", "a\tb", "a\tb"},
+        {"tabs in a row", "\tx\t\ty\t", "\tx\t\ty\t"},
+        {"makefile", "all:\n\tgo build ./...\n", "all:\n\tgo build ./...\n"},
+        {"tsv", "a\tb\nc\td", "a\tb\nc\td"},
+        {"wide", "日本\t語", "日本\t語"},
+
+        // Line endings are normalized.
+        {"crlf", "a\r\nb", "a\nb"},
+        {"crlf and tabs", "a\tb\r\n\tc\r\n", "a\tb\n\tc\n"},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            env, ta := newTextAreaTestEnv(t, NewTextAreaOptions())
+
+            ectx, _ := env.GetElementContext(env.FocusedID())
+            err := ta.SetText(ectx, tc.text)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            if actual := ta.GetText(); actual != tc.expected {
+                t.Errorf("expected %q, got %q", tc.expected, actual)
+            }
+        })
+    }
+}
+
+// Tabs reach the next tab stop of their row.
+func TestTextAreaTabColumns(t *testing.T) {
+    env, ta := newTextAreaTestEnv(t, NewTextAreaOptions())
+
+    ectx, _ := env.GetElementContext(env.FocusedID())
+    err := ta.SetText(ectx, "a\tb\t\tcd")
+    if err == nil {
+        err = env.Render()
+    }
+
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // The column of each position in the line.
+    expected := []int{0, 1, 4, 5, 8, 12, 13, 14}
+    for col, c := range expected {
+        if actual := ta.colOf(textPos{col: col}); actual != c {
+            t.Errorf("position %d: expected column %d, got %d", col, c, actual)
+        }
+    }
+
+    // Clicking inside a tab lands on its nearer side.
+    if p := ta.posAt(0, 2); p.col != 1 {
+        t.Errorf("expected position 1, got %d", p.col)
+    }
+
+    if p := ta.posAt(0, 3); p.col != 2 {
+        t.Errorf("expected position 2, got %d", p.col)
+    }
+}
+
+func TestTextAreaPasteTab(t *testing.T) {
+    env, ta := newTextAreaTestEnv(t, NewTextAreaOptions())
+
+    events := []tcell.Event{
+        tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+        tcell.NewEventPaste(true),
+        tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+        tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone),
+        tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+        tcell.NewEventPaste(false),
+    }
+
+    for _, ev := range events {
+        err := env.ProcessEvent(ev)
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if actual := ta.GetText(); actual != "xa\tb" {
+        t.Errorf("expected %q, got %q", "xa\tb", actual)
+    }
+
+    // The tab stop is measured from the start of the row, not the paste.
+    if c := ta.colOf(ta.caret); c != 5 {
+        t.Errorf("expected the caret at column 5, got %d", c)
+    }
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func lineString(line textLine) string {
+    var sb strings.Builder
+    for _, g := range line.glyphs {
+        sb.WriteString(string(g.runes))
+    }
+
+    return sb.String()
+}
+
+func lineStrings(lines []textLine) []string {
+    strs := make([]string, len(lines))
+    for i, line := range lines {
+        strs[i] = lineString(line)
+    }
+
+    return strs
+}
+
+func TestLayoutText(t *testing.T) {
+    word := NewTextFormat()
+    char := NewTextFormat().WithWrap(WRAP_CHAR)
+    none := NewTextFormat().WithWrap(WRAP_NONE)
+
+    cases := []struct {
+        name string
+        text string
+        width int
+        tf TextFormat
+        expected []string
+    }{
+        {"fits", "hello", 10, word, []string{"hello"}},
+        {"word wrap", "the quick brown fox", 10, word, []string{"the quick", "brown fox"}},
+        {"spaces dropped after break", "ab    cd", 3, word, []string{"ab", "cd"}},
+        {"long word broken", "abcdefgh", 3, word, []string{"abc", "def", "gh"}},
+        {"long word after short", "a bcdefg", 4, word, []string{"a", "bcde", "fg"}},
+        {"newlines", "a\nb\n\nc", 10, word, []string{"a", "b", "", "c"}},
+        {"crlf", "a\r\nb", 10, word, []string{"a", "b"}},
+        {"char wrap", "the quick", 4, char, []string{"the ", "quic", "k"}},
+        {"no wrap", "the quick brown", 4, none, []string{"the quick brown"}},
+        {"tabs", "a\tb", 10, NewTextFormat().WithTabWidth(4), []string{"a   b"}},
+        {"empty", "", 10, word, []string{""}},
+
+        // Wide glyphs take two columns.
+        {"wide wrap", "日本語", 5, char, []string{"日本", "語"}},
+        {"wide word wrap", "ab 日本語", 5, word, []string{"ab", "日本", "語"}},
+        {"wide too wide", "日本", 1, char, []string{"日", "本"}},
+        {"combining mark", "ééé", 2, char, []string{"éé", "é"}},
+        {"emoji zwj", "👩‍👩‍👧x", 2, char, []string{"👩‍👩‍👧", "x"}},
+        {"control dropped", "a\x01b", 10, word, []string{"ab"}},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            lines := layoutText(PlainText(tc.text, tcell.StyleDefault), tc.width, tc.tf)
+            actual := lineStrings(lines)
+
+            if !slices.Equal(actual, tc.expected) {
+                t.Errorf("expected %q, got %q", tc.expected, actual)
+            }
+
+            for i, line := range lines {
+                if line.width != glyphsWidth(line.glyphs) {
+                    t.Errorf("line %d has width %d, its glyphs have width %d",
+                        i, line.width, glyphsWidth(line.glyphs))
+                }
+            }
+        })
+    }
+}
+
+func TestGlyphWidths(t *testing.T) {
+    cases := []struct {
+        text string
+        width int
+        glyphs int
+    }{
+        {"abc", 3, 3},
+        {"日本語", 6, 3},
+        {"é", 1, 1},
+        {"👩‍👩‍👧", 2, 1},
+        {"🇯🇵", 2, 1},
+    }
+
+    for _, tc := range cases {
+        line := toLine(PlainText(tc.text, tcell.StyleDefault), 4)
+        if line.width != tc.width || len(line.glyphs) != tc.glyphs {
+            t.Errorf("%q: expected width %d with %d glyphs, got width %d with %d glyphs",
+                tc.text, tc.width, tc.glyphs, line.width, len(line.glyphs))
+        }
+    }
+}
+
+func TestTruncateLine(t *testing.T) {
+    cases := []struct {
+        name string
+        text string
+        width int
+        force bool
+        expected string
+    }{
+        {"fits", "abc", 3, false, "abc"},
+        {"cut", "abcdef", 4, false, "abc…"},
+        {"forced", "abc", 3, true, "ab…"},
+        {"forced with room", "ab", 5, true, "ab…"},
+        {"only ellipsis", "abc", 1, false, "…"},
+        {"no room", "abc", 0, false, ""},
+        {"wide cut", "日本語", 4, false, "日…"},
+        {"wide cut odd", "日本語", 5, false, "日本…"},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            line := truncateLine(toLine(PlainText(tc.text, tcell.StyleDefault), 4), tc.width, tc.force)
+
+            if actual := lineString(line); actual != tc.expected {
+                t.Errorf("expected %q, got %q", tc.expected, actual)
+            }
+
+            if line.width > max(tc.width, 0) {
+                t.Errorf("line is %d wide, more than %d", line.width, tc.width)
+            }
+        })
+    }
+}
+
+// The ellipsis takes the style of the first glyph it replaces.
+func TestTruncateLineStyle(t *testing.T) {
+    red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+    st := StyledText{{Text: "ab", Style: tcell.StyleDefault}, {Text: "cd", Style: red}}
+
+    line := truncateLine(toLine(st, 4), 3, false)
+    if last := line.glyphs[len(line.glyphs) - 1]; last.style != red {
+        t.Errorf("ellipsis has the wrong style")
+    }
+}
+
+func TestMeasureText(t *testing.T) {
+    cases := []struct {
+        name string
+        text string
+        maxCols int
+        rows, cols int
+    }{
+        {"unbounded", "the quick\nbrown fox jumps", UNBOUNDED, 2, 15},
+        {"wrapped", "the quick brown fox", 10, 2, 9},
+        {"wide", "日本語", 4, 2, 4},
+        {"empty", "", 10, 0, 0},
+        {"no room", "abc", 0, 0, 0},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            rows, cols := measureText(PlainText(tc.text, tcell.StyleDefault), tc.maxCols, NewTextFormat())
+            if rows != tc.rows || cols != tc.cols {
+                t.Errorf("expected %dx%d, got %dx%d", tc.rows, tc.cols, rows, cols)
+            }
+        })
+    }
+}
@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Timers let elements schedule work without needing update ticks forwarded
+// to them. Every timer belongs to an element, when the element is 
+// deregistered, its timers are cancelled.
+//
+// NOTE: Timers run on the environment's clock. The clock advances by the
+// update duration each tick. (See Environment.Tick) So, a timer's
+// resolution is one tick.
+type Timer struct {
+    env *Environment
+    owner ElementID
+
+    // When the timer should next fire. (On the environment's clock)
+    due time.Duration
+
+    // If period is 0, the timer fires once.
+    period time.Duration
+
+    fn func () error
+
+    cancelled bool
+
+    // Position in the environment's timer heap, -1 when not in the heap.
+    index int
+}
+
+// Stops the timer from ever firing again. The timer is removed from the
+// environment and its owner right away.
+// Cancelling a timer more than once has no effect.
+func (t *Timer) Cancel() {
+    if t.cancelled {
+        return
+    }
+
+    t.cancelled = true
+    t.env.removeTimer(t)
+    t.env.forgetTimer(t)
+}
+
+// Returns true if the timer will fire again.
+func (t *Timer) Active() bool {
+    return !t.cancelled
+}
+
+// A min heap of timers ordered by due time.
+type timerHeap []*Timer
+
+func (th timerHeap) Len() int {
+    return len(th)
+}
+
+func (th timerHeap) Less(i, j int) bool {
+    return th[i].due < th[j].due
+}
+
+func (th timerHeap) Swap(i, j int) {
+    th[i], th[j] = th[j], th[i]
+    th[i].index = i
+    th[j].index = j
+}
+
+func (th *timerHeap) Push(x any) {
+    t := x.(*Timer)
+    t.index = len(*th)
+    *th = append(*th, t)
+}
+
+func (th *timerHeap) Pop() any {
+    old := *th
+    t := old[len(old) - 1]
+    old[len(old) - 1] = nil
+    *th = old[:len(old) - 1]
+    t.index = -1
+    return t
+}
+
+func (env *Environment) addTimer(eid ElementID, d time.Duration, 
+    period time.Duration, fn func () error) (*Timer, error) {
+    ee, err := env.getEnvEntry(eid)
+    if err != nil {
+        return nil, err
+    }
+
+    t := &Timer{
+        env: env,
+        owner: eid,
+        due: env.clock + max(d, 0),
+        period: period,
+        fn: fn,
+        cancelled: false,
+        index: -1,
+    }
+
+    heap.Push(&env.timers, t)
+    ee.ectx.timers = append(ee.ectx.timers, t)
+
+    return t, nil
+}
+
+// After schedules fn to be called once, d after now, on behalf of 
+// the given element.
+func (env *Environment) After(eid ElementID, d time.Duration, fn func () error) (*Timer, error) {
+    t, err := env.addTimer(eid, d, 0, fn)
+    if err != nil {
+        return nil, fmt.Errorf("After: %w", err)
+    }
+
+    return t, nil
+}
+
+// Every schedules fn to be called every d, on behalf of the given element.
+// The first call happens d after now.
+func (env *Environment) Every(eid ElementID, d time.Duration, fn func () error) (*Timer, error) {
+    if d <= 0 {
+        return nil, errors.New("Every: Period must be positive")
+    }
+
+    t, err := env.addTimer(eid, d, d, fn)
+    if err != nil {
+        return nil, fmt.Errorf("Every: %w", err)
+    }
+
+    return t, nil
+}
+
+// The time on the environment's clock. This only moves when the environment
+// ticks.
+func (env *Environment) Clock() time.Duration {
+    return env.clock
+}
+
+// Cancels all timers belonging to the given element context.
+func (env *Environment) cancelTimers(ectx *ElementContext) {
+    for _, t := range ectx.timers {
+        t.cancelled = true
+        env.removeTimer(t)
+    }
+
+    ectx.timers = nil
+}
+
+// Removes a timer from the heap if it is still there.
+func (env *Environment) removeTimer(t *Timer) {
+    if t.index != -1 {
+        heap.Remove(&env.timers, t.index)
+    }
+}
+
+// Fires all timers which are due, in order.
+// A repeating timer fires at most once per call, missed periods are skipped.
+func (env *Environment) fireTimers() error {
+    for len(env.timers) > 0 && env.timers[0].due <= env.clock {
+        t := env.timers[0]
+
+        if t.period == 0 {
+            heap.Pop(&env.timers)
+            t.cancelled = true
+            env.forgetTimer(t)
+        } else {
+            t.due += t.period
+            if t.due <= env.clock {
+                t.due = env.clock + t.period
+            }
+
+            heap.Fix(&env.timers, t.index)
+        }
+
+        err := t.fn()
+        if err != nil {
+            return fmt.Errorf("fireTimers: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// Removes a finished or cancelled timer from its owner's list.
+func (env *Environment) forgetTimer(t *Timer) {
+    ee, err := env.getEnvEntry(t.owner)
+    if err != nil {
+        return
+    }
+
+    timers := ee.ectx.timers
+    for i := range timers {
+        if timers[i] == t {
+            ee.ectx.timers = append(timers[:i], timers[i+1:]...)
+            return
+        }
+    }
+}
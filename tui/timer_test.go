@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newTimerTestEnv(t *testing.T) (*Environment, *ElementContext) {
+    s := tcell.NewSimulationScreen("UTF-8")
+    err := s.Init()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    t.Cleanup(s.Fini)
+
+    env := NewEnvironment(s, 10, 10 * time.Millisecond)
+
+    // Ticks go through the root.
+    rid, err := env.Register(NewDefaultElement())
+    if err == nil {
+        err = env.MakeRoot(rid)
+    }
+
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    eid, err := env.Register(NewDefaultElement())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    ectx, _ := env.GetElementContext(eid)
+    return env, ectx
+}
+
+func TestTimerFires(t *testing.T) {
+    env, ectx := newTimerTestEnv(t)
+
+    once, every := 0, 0
+
+    _, err := ectx.After(20 * time.Millisecond, func () error {
+        once++
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    _, err = ectx.Every(10 * time.Millisecond, func () error {
+        every++
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for i := 0; i < 5; i++ {
+        err = env.Tick()
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if once != 1 || every != 5 {
+        t.Errorf("expected 1 and 5 calls, got %d and %d", once, every)
+    }
+
+    // The finished one-shot timer is forgotten.
+    if len(ectx.timers) != 1 || len(env.timers) != 1 {
+        t.Errorf("expected 1 timer left, got %d and %d", len(ectx.timers), len(env.timers))
+    }
+}
+
+// Creating and cancelling timers over and over should not grow anything.
+func TestTimerCancelRemoves(t *testing.T) {
+    env, ectx := newTimerTestEnv(t)
+
+    for i := 0; i < 100; i++ {
+        tm, err := ectx.After(time.Second, func () error {
+            t.Error("cancelled timer fired")
+            return nil
+        })
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        tm.Cancel()
+        tm.Cancel()
+
+        if tm.Active() {
+            t.Fatal("cancelled timer is active")
+        }
+    }
+
+    if len(ectx.timers) != 0 || len(env.timers) != 0 {
+        t.Errorf("expected no timers, got %d and %d", len(ectx.timers), len(env.timers))
+    }
+}
+
+// A repeating timer may cancel itself while firing.
+func TestTimerCancelWhileFiring(t *testing.T) {
+    env, ectx := newTimerTestEnv(t)
+
+    calls := 0
+
+    var tm *Timer
+    tm, err := ectx.Every(10 * time.Millisecond, func () error {
+        calls++
+        tm.Cancel()
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for i := 0; i < 3; i++ {
+        err = env.Tick()
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if calls != 1 || len(env.timers) != 0 {
+        t.Errorf("expected 1 call and no timers, got %d and %d", calls, len(env.timers))
+    }
+}
+
+func TestTimerStaleOwner(t *testing.T) {
+    env, ectx := newTimerTestEnv(t)
+
+    eid := ectx.selfID
+    err := env.Deregister(eid)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    _, err = env.After(eid, time.Second, func () error {
+        return nil
+    })
+    if err == nil {
+        t.Error("expected an error for a stale owner")
+    }
+}
+
+func TestClock(t *testing.T) {
+    env, ectx := newTimerTestEnv(t)
+
+    if ectx.Clock() != 0 {
+        t.Fatalf("expected the clock to start at 0, got %v", ectx.Clock())
+    }
+
+    // The clock only moves when the environment ticks.
+    var at time.Duration
+    _, err := ectx.After(20 * time.Millisecond, func () error {
+        at = ectx.Clock()
+        return nil
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for i := 0; i < 3; i++ {
+        err = env.Tick()
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if env.Clock() != 30 * time.Millisecond || ectx.Clock() != env.Clock() {
+        t.Errorf("expected 30ms after 3 ticks, got %v", env.Clock())
+    }
+
+    if at != 20 * time.Millisecond {
+        t.Errorf("expected the timer to see 20ms, got %v", at)
+    }
+}
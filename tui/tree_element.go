@@ -0,0 +1,688 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// -------------------------------------- Tree Element --------------------------------------
+
+// A tree element shows a hierarchy of nodes. Each expanded node shows its
+// children below it, indented with guides:
+//
+//      ▾ config/
+//      ├─▸ env/
+//      ├─▾ services/
+//      │ └── web.yaml
+//      └── main.yaml
+//
+// A node's children are only loaded the first time it is expanded.
+// Like a list, only the visible rows are drawn.
+//
+// Keys:
+//      Up/Down             Move the selection.
+//      PageUp/PageDown     Move the selection by a page.
+//      Home/End            Select the first/last row.
+//      Right               Expand the selected node, or move to its first
+//                          child if it is already expanded.
+//      Left                Collapse the selected node, or move to its
+//                          parent if it is already collapsed.
+//      Space               Expand or collapse the selected node.
+//      Enter               Activate the selected node.
+//
+// Clicking a node's arrow expands or collapses it. Clicking a node selects
+// it, clicking the selected node activates it.
+
+type TreeNode interface {
+    Label() StyledText
+
+    // Returns true if the node may have children. This should not need
+    // to load the children.
+    HasChildren() bool
+
+    // Only called when the node is first expanded.
+    Children() ([]TreeNode, error)
+}
+
+const (
+    treeExpanded = "▾ "
+    treeCollapsed = "▸ "
+    treeLeaf = "─ "
+
+    treeBranch = "├─"
+    treeLastBranch = "└─"
+    treeGuide = "│ "
+    treeNoGuide = "  "
+)
+
+// Columns taken by each level of indentation.
+const treeIndent = 2
+
+type TreeOptions struct {
+    showRoot bool
+
+    guideStyle *tcell.Style
+    selectedStyle *tcell.Style
+
+    onSelect func (*ElementContext, TreeNode) error
+    onActivate func (*ElementContext, TreeNode) error
+
+    onLoadError func (*ElementContext, TreeNode, error) error
+}
+
+// By default, the root is shown (and expanded).
+func NewTreeOptions() TreeOptions {
+    return TreeOptions{
+        showRoot: true,
+        guideStyle: nil,
+        selectedStyle: nil,
+        onSelect: nil,
+        onActivate: nil,
+        onLoadError: nil,
+    }
+}
+
+// The root's children are shown at the top level instead of the root.
+func (to TreeOptions) WithoutRoot() TreeOptions {
+    to.showRoot = false
+    return to
+}
+
+// By default, guides and arrows are drawn dimmed.
+func (to TreeOptions) WithGuideStyle(s tcell.Style) TreeOptions {
+    to.guideStyle = &s
+    return to
+}
+
+// By default, the selected row is drawn reversed.
+func (to TreeOptions) WithSelectedStyle(s tcell.Style) TreeOptions {
+    to.selectedStyle = &s
+    return to
+}
+
+// Called with the newly selected node every time the user moves the
+// selection.
+func (to TreeOptions) OnSelect(fn func (*ElementContext, TreeNode) error) TreeOptions {
+    to.onSelect = fn
+    return to
+}
+
+// Called with the selected node when it is activated.
+func (to TreeOptions) OnActivate(fn func (*ElementContext, TreeNode) error) TreeOptions {
+    to.onActivate = fn
+    return to
+}
+
+// Called when a node's children can't be loaded. The node is left
+// collapsed, and loading is tried again the next time it is expanded.
+// Without this callback, load errors are ignored.
+func (to TreeOptions) OnLoadError(fn func (*ElementContext, TreeNode, error) error) TreeOptions {
+    to.onLoadError = fn
+    return to
+}
+
+type treeItem struct {
+    node TreeNode
+    parent *treeItem
+
+    // Visible depth, top level rows have depth 0.
+    depth int
+
+    // Whether this is the last of its parent's children.
+    last bool
+
+    expanded bool
+    loaded bool
+    children []*treeItem
+}
+
+// Loads the item's children if they haven't been loaded yet.
+func (ti *treeItem) load() error {
+    if ti.loaded {
+        return nil
+    }
+
+    nodes, err := ti.node.Children()
+    if err != nil {
+        return err
+    }
+
+    ti.children = make([]*treeItem, len(nodes))
+    for i, n := range nodes {
+        ti.children[i] = &treeItem{
+            node: n,
+            parent: ti,
+            depth: ti.depth + 1,
+            last: i == len(nodes) - 1,
+        }
+    }
+
+    ti.loaded = true
+    return nil
+}
+
+type TreeElement struct {
+    *DefaultElement
+
+    style tcell.Style
+    guideStyle tcell.Style
+    selectedStyle tcell.Style
+
+    opts TreeOptions
+
+    root *treeItem
+
+    // The visible items in display order.
+    rows []*treeItem
+
+    // -1 when there are no rows.
+    selected int
+
+    // The first visible row.
+    rowOff int
+
+    // Set when a press lands on the row which was already selected.
+    pressedSelected bool
+}
+
+// The root's children are loaded right away, so this can fail.
+func NewTreeElement(s tcell.Style, root TreeNode, opts TreeOptions) (*TreeElement, error) {
+    gs := s.Dim(true)
+    if opts.guideStyle != nil {
+        gs = *opts.guideStyle
+    }
+
+    ss := s.Reverse(true)
+    if opts.selectedStyle != nil {
+        ss = *opts.selectedStyle
+    }
+
+    // A hidden root sits one level above the top level.
+    depth := 0
+    if !opts.showRoot {
+        depth = -1
+    }
+
+    te := &TreeElement{
+        DefaultElement: NewDefaultElement(),
+        style: s,
+        guideStyle: gs,
+        selectedStyle: ss,
+        opts: opts,
+        root: &treeItem{node: root, depth: depth, last: true, expanded: true},
+        rows: make([]*treeItem, 0),
+        selected: -1,
+        rowOff: 0,
+        pressedSelected: false,
+    }
+
+    err := te.root.load()
+    if err != nil {
+        return nil, err
+    }
+
+    te.flatten()
+    return te, nil
+}
+
+func TreeElementF(s tcell.Style, root TreeNode, opts TreeOptions) ElementFactory {
+    return func (env *Environment) (ElementID, error) {
+        te, err := NewTreeElement(s, root, opts)
+        if err != nil {
+            return -1, err
+        }
+
+        return env.Register(te)
+    }
+}
+
+func (te *TreeElement) AcceptsFocus() bool {
+    return true
+}
+
+// Rebuilds the visible rows, keeping the selected item selected. If the
+// selected item is no longer visible, its nearest visible ancestor is
+// selected.
+func (te *TreeElement) flatten() {
+    var sel *treeItem
+    if te.selected != -1 {
+        sel = te.rows[te.selected]
+    }
+
+    te.rows = te.rows[:0]
+
+    var visit func (ti *treeItem)
+    visit = func (ti *treeItem) {
+        if ti.depth >= 0 {
+            te.rows = append(te.rows, ti)
+        }
+
+        if ti.expanded {
+            for _, c := range ti.children {
+                visit(c)
+            }
+        }
+    }
+
+    visit(te.root)
+
+    te.selected = -1
+    for ; sel != nil && te.selected == -1; sel = sel.parent {
+        te.selected = te.rowOf(sel)
+    }
+
+    if te.selected == -1 && len(te.rows) > 0 {
+        te.selected = 0
+    }
+
+    te.scrollToSelected()
+}
+
+// Returns the row of the given item, or -1 if it isn't visible.
+func (te *TreeElement) rowOf(ti *treeItem) int {
+    for i, r := range te.rows {
+        if r == ti {
+            return i
+        }
+    }
+
+    return -1
+}
+
+// Returns the selected node, or nil if the tree is empty.
+func (te *TreeElement) GetSelected() TreeNode {
+    if te.selected == -1 {
+        return nil
+    }
+
+    return te.rows[te.selected].node
+}
+
+// Expands or collapses the given item.
+func (te *TreeElement) setExpanded(ectx *ElementContext, ti *treeItem, expanded bool) error {
+    if expanded == ti.expanded || (expanded && !ti.node.HasChildren()) {
+        return nil
+    }
+
+    if expanded {
+        err := ti.load()
+        if err != nil {
+            if te.opts.onLoadError != nil {
+                return te.opts.onLoadError(ectx, ti.node, err)
+            }
+
+            return nil
+        }
+    }
+
+    ti.expanded = expanded
+    te.flatten()
+
+    ectx.RequestLayout()
+    ectx.SetDrawFlag()
+
+    return nil
+}
+
+// Moves the selection, returns true if it changed.
+func (te *TreeElement) moveSelection(i int) bool {
+    n := len(te.rows)
+    if n == 0 {
+        return false
+    }
+
+    i = max(min(i, n - 1), 0)
+    if i == te.selected {
+        return false
+    }
+
+    te.selected = i
+    te.scrollToSelected()
+
+    return true
+}
+
+// Moves the selection and calls the select callback if it changed.
+func (te *TreeElement) selectRow(ectx *ElementContext, i int) error {
+    if !te.moveSelection(i) {
+        return nil
+    }
+
+    ectx.SetDrawFlag()
+
+    if te.opts.onSelect != nil {
+        return te.opts.onSelect(ectx, te.rows[te.selected].node)
+    }
+
+    return nil
+}
+
+func (te *TreeElement) activate(ectx *ElementContext) error {
+    if te.selected == -1 || te.opts.onActivate == nil {
+        return nil
+    }
+
+    return te.opts.onActivate(ectx, te.rows[te.selected].node)
+}
+
+// Keeps the offset within the rows.
+func (te *TreeElement) clampOffset() {
+    te.rowOff = max(min(te.rowOff, len(te.rows) - te.GetRows()), 0)
+}
+
+func (te *TreeElement) scrollToSelected() {
+    if te.selected < te.rowOff {
+        te.rowOff = te.selected
+    }
+
+    if te.GetRows() > 0 && te.selected >= te.rowOff + te.GetRows() {
+        te.rowOff = te.selected - te.GetRows() + 1
+    }
+
+    te.clampOffset()
+}
+
+// Returns the guides, branch and arrow drawn before the given item's label.
+func (te *TreeElement) prefix(ti *treeItem) string {
+    arrow := treeLeaf
+    switch {
+    case ti.expanded && ti.node.HasChildren():
+        arrow = treeExpanded
+    case ti.node.HasChildren():
+        arrow = treeCollapsed
+    case ti.depth == 0:
+        arrow = treeNoGuide
+    }
+
+    if ti.depth == 0 {
+        return arrow
+    }
+
+    branch := treeBranch
+    if ti.last {
+        branch = treeLastBranch
+    }
+
+    parts := []string{arrow, branch}
+
+    for p := ti.parent; p != nil && p.depth > 0; p = p.parent {
+        if p.last {
+            parts = append(parts, treeNoGuide)
+        } else {
+            parts = append(parts, treeGuide)
+        }
+    }
+
+    s := ""
+    for i := len(parts) - 1; i >= 0; i-- {
+        s += parts[i]
+    }
+
+    return s
+}
+
+// A tree would like to show every visible row. Only the first few rows
+// are measured. (See listMeasureLimit)
+func (te *TreeElement) Measure(ectx *ElementContext, cons Constraints) SizeHint {
+    tabWidth := NewTextFormat().tabWidth
+
+    cols := 0
+    for _, ti := range te.rows[:min(len(te.rows), listMeasureLimit)] {
+        w := (ti.depth + 1) * treeIndent + toLine(ti.node.Label(), tabWidth).width
+        cols = max(cols, w)
+    }
+
+    return SizeHint{
+        PrefRows: len(te.rows),
+        PrefCols: cols,
+        MinRows: min(len(te.rows), 1),
+        MinCols: 0,
+        MaxRows: UNBOUNDED,
+        MaxCols: UNBOUNDED,
+    }
+}
+
+func (te *TreeElement) Resize(ectx *ElementContext, r, c int, rows, cols int) error {
+    err := te.DefaultElement.Resize(ectx, r, c, rows, cols)
+    if err != nil {
+        return err
+    }
+
+    te.scrollToSelected()
+    return nil
+}
+
+// Performs the action of the given key.
+// handled is false if the tree does not use the key.
+func (te *TreeElement) handleKey(ectx *ElementContext, ev *tcell.EventKey) (bool, error) {
+    page := max(te.GetRows() - 1, 1)
+
+    switch ev.Key() {
+    case tcell.KeyUp:
+        return true, te.selectRow(ectx, te.selected - 1)
+    case tcell.KeyDown:
+        return true, te.selectRow(ectx, te.selected + 1)
+    case tcell.KeyPgUp:
+        return true, te.selectRow(ectx, te.selected - page)
+    case tcell.KeyPgDn:
+        return true, te.selectRow(ectx, te.selected + page)
+    case tcell.KeyHome:
+        return true, te.selectRow(ectx, 0)
+    case tcell.KeyEnd:
+        return true, te.selectRow(ectx, len(te.rows) - 1)
+    case tcell.KeyEnter:
+        return true, te.activate(ectx)
+    }
+
+    if te.selected == -1 {
+        return false, nil
+    }
+
+    ti := te.rows[te.selected]
+
+    switch {
+    case ev.Key() == tcell.KeyRight:
+        if ti.expanded && len(ti.children) > 0 {
+            return true, te.selectRow(ectx, te.selected + 1)
+        }
+
+        return true, te.setExpanded(ectx, ti, true)
+
+    case ev.Key() == tcell.KeyLeft:
+        if ti.expanded && ti.node.HasChildren() {
+            return true, te.setExpanded(ectx, ti, false)
+        }
+
+        if ti.parent != nil && ti.parent.depth >= 0 {
+            return true, te.selectRow(ectx, te.rowOf(ti.parent))
+        }
+
+        return true, nil
+
+    case ev.Key() == tcell.KeyRune && ev.Rune() == ' ':
+        return true, te.setExpanded(ectx, ti, !ti.expanded)
+    }
+
+    return false, nil
+}
+
+func (te *TreeElement) HandleEvent(ectx *ElementContext, ev tcell.Event) error {
+    if ectx.EventHandled() {
+        return nil
+    }
+
+    switch tev := ev.(type) {
+    case *MouseEvent:
+        return te.handleMouse(ectx, tev)
+
+    case *tcell.EventKey:
+        handled, err := te.handleKey(ectx, tev)
+        if handled {
+            ectx.MarkHandled()
+        }
+
+        return err
+    }
+
+    return nil
+}
+
+func (te *TreeElement) handleMouse(ectx *ElementContext, ev *MouseEvent) error {
+    x, y := ev.Position()
+    i := te.rowOff + y
+
+    switch ev.Action() {
+    case MOUSE_PRESS:
+        ectx.MarkHandled()
+
+        err := ectx.Focus()
+        if err != nil {
+            return err
+        }
+
+        if i >= len(te.rows) {
+            return nil
+        }
+
+        ti := te.rows[i]
+        te.pressedSelected = i == te.selected
+
+        err = te.selectRow(ectx, i)
+        if err != nil {
+            return err
+        }
+
+        // The arrow is the last indent before the label.
+        arrow := ti.depth * treeIndent
+        if arrow <= x && x < arrow + treeIndent {
+            te.pressedSelected = false
+            return te.setExpanded(ectx, ti, !ti.expanded)
+        }
+
+    case MOUSE_CLICK:
+        ectx.MarkHandled()
+
+        if te.pressedSelected && i == te.selected {
+            return te.activate(ectx)
+        }
+
+    case MOUSE_WHEEL:
+        buttons := ev.Buttons()
+
+        switch {
+        case buttons & tcell.WheelUp != 0:
+            te.rowOff -= wheelRows
+        case buttons & tcell.WheelDown != 0:
+            te.rowOff += wheelRows
+        default:
+            return nil
+        }
+
+        // Scrolling does not move the selection.
+        ectx.MarkHandled()
+        te.clampOffset()
+        ectx.SetDrawFlag()
+    }
+
+    return nil
+}
+
+func (te *TreeElement) Draw(cv *Canvas) {
+    cv.Fill(' ', te.style)
+
+    tabWidth := NewTextFormat().tabWidth
+
+    for r := 0; r < cv.Rows() && te.rowOff + r < len(te.rows); r++ {
+        i := te.rowOff + r
+        ti := te.rows[i]
+
+        guideStyle := te.guideStyle
+        if i == te.selected {
+            guideStyle = te.selectedStyle
+            cv.FillRect(r, 0, 1, cv.Cols(), ' ', te.selectedStyle)
+        }
+
+        c := cv.PrintStyled(r, 0, te.prefix(ti), guideStyle)
+
+        line := truncateLine(toLine(ti.node.Label(), tabWidth), cv.Cols() - c, false)
+        if i == te.selected {
+            for j := range line.glyphs {
+                line.glyphs[j].style = te.selectedStyle
+            }
+        }
+
+        drawLine(cv, r, c, line)
+    }
+}
+
+// -------------------------------------- File Tree Node --------------------------------------
+
+// A file tree node is a tree node for a file or directory.
+// Directories are listed before files, each sorted by name.
+type FileTreeNode struct {
+    path string
+    dir bool
+
+    style tcell.Style
+}
+
+func NewFileTreeNode(path string, s tcell.Style) (*FileTreeNode, error) {
+    info, err := os.Stat(path)
+    if err != nil {
+        return nil, err
+    }
+
+    return &FileTreeNode{
+        path: path,
+        dir: info.IsDir(),
+        style: s,
+    }, nil
+}
+
+func (fn *FileTreeNode) Path() string {
+    return fn.path
+}
+
+func (fn *FileTreeNode) IsDir() bool {
+    return fn.dir
+}
+
+// Directories end in a slash.
+func (fn *FileTreeNode) Label() StyledText {
+    name := filepath.Base(fn.path)
+    if fn.dir {
+        name += "/"
+    }
+
+    return PlainText(name, fn.style)
+}
+
+func (fn *FileTreeNode) HasChildren() bool {
+    return fn.dir
+}
+
+func (fn *FileTreeNode) Children() ([]TreeNode, error) {
+    entries, err := os.ReadDir(fn.path)
+    if err != nil {
+        return nil, err
+    }
+
+    // ReadDir sorts by name, so a stable sort keeps names in order.
+    sort.SliceStable(entries, func (i, j int) bool {
+        return entries[i].IsDir() && !entries[j].IsDir()
+    })
+
+    nodes := make([]TreeNode, len(entries))
+    for i, e := range entries {
+        nodes[i] = &FileTreeNode{
+            path: filepath.Join(fn.path, e.Name()),
+            dir: e.IsDir(),
+            style: fn.style,
+        }
+    }
+
+    return nodes, nil
+}
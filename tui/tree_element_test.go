@@ -0,0 +1,194 @@
+package tui_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/chathamabate/thingy/tui/tuitest"
+	"github.com/gdamore/tcell/v2"
+)
+
+type testNode struct {
+    name string
+    children []tui.TreeNode
+
+    // Returned from Children when set.
+    err error
+}
+
+func (tn *testNode) Label() tui.StyledText {
+    return tui.PlainText(tn.name, plain)
+}
+
+func (tn *testNode) HasChildren() bool {
+    return tn.err != nil || len(tn.children) > 0
+}
+
+func (tn *testNode) Children() ([]tui.TreeNode, error) {
+    return tn.children, tn.err
+}
+
+func newTreeHarness(t *testing.T, root tui.TreeNode, opts tui.TreeOptions) (*tuitest.Harness, *tui.TreeElement) {
+    te, err := tui.NewTreeElement(plain, root, opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // Clicks the first row, away from its arrow.
+    h := newFocusedHarness(t, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(te)
+    }, 19, 0)
+
+    return h, te
+}
+
+func TestTreeEmpty(t *testing.T) {
+    calls := 0
+    opts := tui.NewTreeOptions().
+        WithoutRoot().
+        OnSelect(func (ectx *tui.ElementContext, n tui.TreeNode) error {
+            calls++
+            return nil
+        }).
+        OnActivate(func (ectx *tui.ElementContext, n tui.TreeNode) error {
+            calls++
+            return nil
+        })
+
+    te, err := tui.NewTreeElement(plain, &testNode{name: "root"}, opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    testEmptyNavigation(t, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(te)
+    }, func () bool {
+        return te.GetSelected() != nil
+    }, &calls)
+}
+
+// Without a callback, a node which fails to load stays collapsed.
+func TestTreeLoadError(t *testing.T) {
+    bad := &testNode{name: "bad", err: errors.New("no access")}
+    root := &testNode{name: "root", children: []tui.TreeNode{bad}}
+
+    h, te := newTreeHarness(t, root, tui.NewTreeOptions().WithoutRoot())
+
+    h.Key(tcell.KeyRight, tcell.ModNone)
+    h.Type(" ")
+
+    if te.GetSelected() != bad {
+        t.Fatalf("expected %q selected", "bad")
+    }
+
+    h.AssertGolden("tree_load_error")
+
+    // Loading is tried again on the next expand.
+    bad.err = nil
+    bad.children = []tui.TreeNode{&testNode{name: "child"}}
+    h.Key(tcell.KeyRight, tcell.ModNone)
+
+    h.AssertGolden("tree_load_retried")
+}
+
+func TestTreeLoadErrorCallback(t *testing.T) {
+    bad := &testNode{name: "bad", err: errors.New("no access")}
+    root := &testNode{name: "root", children: []tui.TreeNode{bad}}
+
+    var seen error
+    opts := tui.NewTreeOptions().
+        WithoutRoot().
+        OnLoadError(func (ectx *tui.ElementContext, n tui.TreeNode, err error) error {
+            seen = err
+            return nil
+        })
+
+    h, _ := newTreeHarness(t, root, opts)
+    h.Key(tcell.KeyRight, tcell.ModNone)
+
+    if seen != bad.err {
+        t.Errorf("expected %v, got %v", bad.err, seen)
+    }
+}
+
+// root holds a and b. a holds a1 and a2, a2 holds a2x and b holds b1.
+func newGuideTree() *testNode {
+    a2 := &testNode{name: "a2", children: []tui.TreeNode{&testNode{name: "a2x"}}}
+    a := &testNode{name: "a", children: []tui.TreeNode{&testNode{name: "a1"}, a2}}
+    b := &testNode{name: "b", children: []tui.TreeNode{&testNode{name: "b1"}}}
+
+    return &testNode{name: "root", children: []tui.TreeNode{a, b}}
+}
+
+// The lines on screen without trailing spaces.
+func screenLines(h *tuitest.Harness) []string {
+    lines := strings.Split(strings.TrimRight(h.Text(), "\n"), "\n")
+    for i := range lines {
+        lines[i] = strings.TrimRight(lines[i], " ")
+    }
+
+    return lines
+}
+
+func TestTreeGuides(t *testing.T) {
+    te, err := tui.NewTreeElement(plain, newGuideTree(), tui.NewTreeOptions())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    h := tuitest.New(t, 7, 20, func (env *tui.Environment) (tui.ElementID, error) {
+        return env.Register(te)
+    })
+
+    // Expands a, then a2.
+    h.Click(2, 1)
+    h.Click(4, 3)
+
+    expectLog(t, screenLines(h),
+        "▾ root",
+        "├─▾ a",
+        "│ ├── a1",
+        "│ └─▾ a2",
+        "│   └── a2x",
+        "└─▸ b",
+        "")
+}
+
+// Pressing a node's arrow toggles it. Pressing anywhere else on its row
+// only selects it.
+func TestTreeArrowClick(t *testing.T) {
+    collapsed := []string{"▾ root", "├─▸ a", "└─▸ b", ""}
+    expanded := []string{"▾ root", "├─▾ a", "│ ├── a1", "│ └─▸ a2"}
+
+    cases := []struct {
+        name string
+        clicks []int
+        expected []string
+    }{
+        {"arrow", []int{2}, expanded},
+        {"arrow end", []int{3}, expanded},
+        {"arrow twice", []int{2, 2}, collapsed},
+        {"branch", []int{1}, collapsed},
+        {"label", []int{4}, collapsed},
+        {"after label", []int{10}, collapsed},
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func (t *testing.T) {
+            h, te := newTreeHarness(t, newGuideTree(), tui.NewTreeOptions())
+
+            // a is on row 1.
+            for _, x := range tc.clicks {
+                h.Click(x, 1)
+            }
+
+            if sel := te.GetSelected(); sel == nil || sel.Label().String() != "a" {
+                t.Errorf("expected %q selected", "a")
+            }
+
+            expectLog(t, screenLines(h), tc.expected...)
+        })
+    }
+}
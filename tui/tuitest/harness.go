@@ -0,0 +1,292 @@
+// Package tuitest runs a tui environment on a simulated screen.
+//
+// Events are processed one at a time, as soon as they are injected, and the
+// screen is rendered after each one. Nothing sleeps, so tests are
+// deterministic.
+//
+// Rendered screens can be compared against golden files stored in the
+// calling package's testdata directory. Run tests with -update to
+// regenerate golden files:
+//
+//      go test ./... -update
+//
+// Setting TUITEST_UPDATE=1 does the same.
+package tuitest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/gdamore/tcell/v2"
+)
+
+var update = flag.Bool("update", false, "tuitest: Write golden files rather than comparing against them")
+
+// A fallback for -update. When this environment variable is set to anything
+// but "", golden files are written rather than compared against.
+const UPDATE_ENV = "TUITEST_UPDATE"
+
+func updating() bool {
+    return *update || os.Getenv(UPDATE_ENV) != ""
+}
+
+// Keys given to styles in snapshots. A snapshot can hold at most this many
+// distinct styles besides the default.
+const styleKeys = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// The max capacity of every harness environment.
+const harnessCapacity = tui.UNLIMITED_CAPACITY
+
+type Harness struct {
+    t testing.TB
+
+    screen tcell.SimulationScreen
+    env *tui.Environment
+}
+
+// New creates a harness with a screen of the given size. The element
+// created by ef is made the root, then the screen is rendered.
+//
+// The environment is cleaned up when the test finishes.
+func New(t testing.TB, rows, cols int, ef tui.ElementFactory) *Harness {
+    t.Helper()
+
+    s := tcell.NewSimulationScreen("UTF-8")
+    err := s.Init()
+    if err != nil {
+        t.Fatalf("tuitest.New: %v", err)
+    }
+
+    s.SetSize(cols, rows)
+
+    h := &Harness{
+        t: t,
+        screen: s,
+        env: tui.NewEnvironment(s, harnessCapacity, 100 * time.Millisecond),
+    }
+
+    t.Cleanup(func() {
+        h.env.DeregisterAll()
+        s.Fini()
+    })
+
+    eid, err := h.env.CreateAndRegister(ef)
+    if err != nil {
+        t.Fatalf("tuitest.New: %v", err)
+    }
+
+    err = h.env.MakeRoot(eid)
+    if err != nil {
+        t.Fatalf("tuitest.New: %v", err)
+    }
+
+    h.render()
+
+    return h
+}
+
+func (h *Harness) Env() *tui.Environment {
+    return h.env
+}
+
+func (h *Harness) Screen() tcell.SimulationScreen {
+    return h.screen
+}
+
+func (h *Harness) render() {
+    h.t.Helper()
+
+    err := h.env.Render()
+    if err != nil {
+        h.t.Fatalf("tuitest: %v", err)
+    }
+}
+
+// Event processes the given event then renders.
+func (h *Harness) Event(ev tcell.Event) {
+    h.t.Helper()
+
+    err := h.env.ProcessEvent(ev)
+    if err != nil {
+        h.t.Fatalf("tuitest: %v", err)
+    }
+
+    h.render()
+}
+
+func (h *Harness) Key(k tcell.Key, mod tcell.ModMask) {
+    h.t.Helper()
+    h.Event(tcell.NewEventKey(k, 0, mod))
+}
+
+// Type sends one rune key event per rune of the given string.
+func (h *Harness) Type(str string) {
+    h.t.Helper()
+
+    for _, r := range str {
+        h.Event(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+    }
+}
+
+func (h *Harness) Mouse(x, y int, buttons tcell.ButtonMask, mod tcell.ModMask) {
+    h.t.Helper()
+    h.Event(tcell.NewEventMouse(x, y, buttons, mod))
+}
+
+// Click presses then releases the primary button at the given position.
+func (h *Harness) Click(x, y int) {
+    h.t.Helper()
+
+    h.Mouse(x, y, tcell.Button1, tcell.ModNone)
+    h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+func (h *Harness) Resize(rows, cols int) {
+    h.t.Helper()
+
+    h.screen.SetSize(cols, rows)
+    h.Event(tcell.NewEventResize(cols, rows))
+}
+
+// Flush performs all posted work then renders.
+// (See Environment.Post)
+func (h *Harness) Flush() {
+    h.t.Helper()
+
+    err := h.env.ProcessPosted()
+    if err != nil {
+        h.t.Fatalf("tuitest: %v", err)
+    }
+
+    h.render()
+}
+
+// Tick sends n update ticks then renders.
+func (h *Harness) Tick(n int) {
+    h.t.Helper()
+
+    for i := 0; i < n; i++ {
+        err := h.env.Tick()
+        if err != nil {
+            h.t.Fatalf("tuitest: %v", err)
+        }
+    }
+
+    h.render()
+}
+
+// Text returns the characters on screen, one line per row.
+func (h *Harness) Text() string {
+    cells, cols, rows := h.screen.GetContents()
+
+    var sb strings.Builder
+    for r := 0; r < rows; r++ {
+        for c := 0; c < cols; c++ {
+            runes := cells[r*cols + c].Runes
+            if len(runes) == 0 {
+                sb.WriteRune(' ')
+            } else {
+                sb.WriteString(string(runes))
+            }
+        }
+
+        sb.WriteRune('\n')
+    }
+
+    return sb.String()
+}
+
+func describeStyle(st tcell.Style) string {
+    fg, bg, attrs := st.Decompose()
+    return fmt.Sprintf("fg=%s bg=%s attrs=%d", fg.String(), bg.String(), attrs)
+}
+
+// Snapshot returns the characters on screen followed by a grid of style keys
+// and a legend describing each key. Cells with the default style have the
+// key '.', other styles are given keys from styleKeys in order of
+// appearance. The test fails if the screen has too many styles for the keys.
+func (h *Harness) Snapshot() string {
+    h.t.Helper()
+
+    cells, cols, rows := h.screen.GetContents()
+    keyRunes := []rune(styleKeys)
+
+    keys := make(map[tcell.Style]rune)
+    legend := make([]string, 0)
+
+    var sb strings.Builder
+    sb.WriteString(h.Text())
+    sb.WriteString("--- styles\n")
+
+    for r := 0; r < rows; r++ {
+        for c := 0; c < cols; c++ {
+            st := cells[r*cols + c].Style
+
+            if st == tcell.StyleDefault {
+                sb.WriteRune('.')
+                continue
+            }
+
+            key, ok := keys[st]
+            if !ok {
+                if len(keys) == len(keyRunes) {
+                    h.t.Fatalf("tuitest: Snapshot: More than %d styles on screen", len(keyRunes))
+                }
+
+                key = keyRunes[len(keys)]
+                keys[st] = key
+                legend = append(legend, fmt.Sprintf("%c: %s\n", key, describeStyle(st)))
+            }
+
+            sb.WriteRune(key)
+        }
+
+        sb.WriteRune('\n')
+    }
+
+    sb.WriteString("--- legend\n")
+    for _, line := range legend {
+        sb.WriteString(line)
+    }
+
+    return sb.String()
+}
+
+// AssertGolden compares the current snapshot with testdata/<name>.golden.
+// When run with -update (or UPDATE_ENV set), the golden file is written
+// instead.
+func (h *Harness) AssertGolden(name string) {
+    h.t.Helper()
+
+    path := filepath.Join("testdata", name + ".golden")
+    actual := h.Snapshot()
+
+    if updating() {
+        err := os.MkdirAll("testdata", 0755)
+        if err == nil {
+            err = os.WriteFile(path, []byte(actual), 0644)
+        }
+
+        if err != nil {
+            h.t.Fatalf("tuitest: %v", err)
+        }
+
+        return
+    }
+
+    expected, err := os.ReadFile(path)
+    if err != nil {
+        h.t.Fatalf("tuitest: %v (run with -update to create it)", err)
+    }
+
+    if string(expected) != actual {
+        h.t.Errorf("tuitest: %s does not match the screen.\n--- expected\n%s--- actual\n%s",
+            path, expected, actual)
+    }
+}
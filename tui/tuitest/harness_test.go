@@ -0,0 +1,71 @@
+package tuitest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chathamabate/thingy/tui"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Draws each cell of its first row in a different color.
+type paletteElement struct {
+    *tui.DefaultElement
+}
+
+func (pe *paletteElement) Draw(cv *tui.Canvas) {
+    for c := 0; c < cv.Cols(); c++ {
+        cv.SetCellAt(0, c, 'x', tcell.StyleDefault.Foreground(tcell.PaletteColor(c + 1)))
+    }
+}
+
+func paletteElementF(env *tui.Environment) (tui.ElementID, error) {
+    return env.Register(&paletteElement{DefaultElement: tui.NewDefaultElement()})
+}
+
+// Records fatal failures rather than failing the real test.
+type fatalRecorder struct {
+    testing.TB
+
+    msg string
+}
+
+func (fr *fatalRecorder) Helper() {
+}
+
+func (fr *fatalRecorder) Fatalf(format string, args ...any) {
+    fr.msg = fmt.Sprintf(format, args...)
+
+    // Like testing.T, Fatalf never returns.
+    panic(fr)
+}
+
+func TestSnapshotStyleKeys(t *testing.T) {
+    h := New(t, 1, len(styleKeys), paletteElementF)
+
+    lines := strings.Split(h.Snapshot(), "\n")
+    if lines[2] != styleKeys {
+        t.Fatalf("style keys: expected %q, got %q", styleKeys, lines[2])
+    }
+}
+
+func TestSnapshotTooManyStyles(t *testing.T) {
+    h := New(t, 1, len(styleKeys) + 1, paletteElementF)
+
+    fr := &fatalRecorder{TB: t}
+    h.t = fr
+
+    defer func () {
+        if r := recover(); r != fr {
+            panic(r)
+        }
+
+        if !strings.Contains(fr.msg, "styles on screen") {
+            t.Fatalf("unexpected failure: %s", fr.msg)
+        }
+    }()
+
+    h.Snapshot()
+    t.Fatal("expected Snapshot to fail")
+}
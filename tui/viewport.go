@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// A viewport is a window into an element (and its descendants).
+// 
+// An element with a viewport is laid out in its own coordinate space.
+// When drawn, the point (xOff, yOff) of this space appears on screen at (x, y).
+// Nothing outside of the viewport's width and height is drawn.
+type Viewport struct {
+    // Coordinates of where the viewport should be 
+    // rendered on screen.
+    x, y int
+
+    // Offset into the element which is being rendered.
+    xOff, yOff int
+
+    // Dimmensions of the viewport.
+    width, height int
+}
+
+func NewViewport(x, y int, xOff, yOff int, width, height int) Viewport {
+    return Viewport{
+        x: x,
+        y: y,
+        xOff: xOff,
+        yOff: yOff,
+        width: width,
+        height: height,
+    }
+}
+
+// A viewport screen translates and clips all drawing done through it
+// according to a viewport.
+//
+// NOTE: Viewport screens can wrap other viewport screens, this is how 
+// nested viewports work.
+type viewportScreen struct {
+    tcell.Screen
+
+    vp Viewport
+}
+
+func newViewportScreen(s tcell.Screen, vp Viewport) *viewportScreen {
+    return &viewportScreen{
+        Screen: s,
+        vp: vp,
+    }
+}
+
+// Returns the screen coordinates of the given element coordinates.
+// ok is false if the point lies outside the viewport.
+func (vs *viewportScreen) translate(x, y int) (int, int, bool) {
+    vx := x - vs.vp.xOff
+    vy := y - vs.vp.yOff
+
+    if vx < 0 || vy < 0 || vs.vp.width <= vx || vs.vp.height <= vy {
+        return 0, 0, false
+    }
+
+    return vs.vp.x + vx, vs.vp.y + vy, true
+}
+
+func (vs *viewportScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+    sx, sy, ok := vs.translate(x, y)
+    if ok {
+        vs.Screen.SetContent(sx, sy, primary, combining, style)
+    }
+}
+
+func (vs *viewportScreen) SetCell(x, y int, style tcell.Style, ch ...rune) {
+    if len(ch) > 0 {
+        vs.SetContent(x, y, ch[0], ch[1:], style)
+    } else {
+        vs.SetContent(x, y, ' ', nil, style)
+    }
+}
+
+func (vs *viewportScreen) ShowCursor(x, y int) {
+    sx, sy, ok := vs.translate(x, y)
+    if ok {
+        vs.Screen.ShowCursor(sx, sy)
+    } else {
+        vs.Screen.HideCursor()
+    }
+}
+
+// Fill only fills the visible part of the element.
+func (vs *viewportScreen) Fill(ch rune, style tcell.Style) {
+    for y := vs.vp.yOff; y < vs.vp.yOff + vs.vp.height; y++ {
+        for x := vs.vp.xOff; x < vs.vp.xOff + vs.vp.width; x++ {
+            vs.SetContent(x, y, ch, nil, style)
+        }
+    }
+}
+
+func (vs *viewportScreen) Clear() {
+    vs.Fill(' ', tcell.StyleDefault)
+}
+
+// Points outside the viewport read as blank cells.
+func (vs *viewportScreen) GetContent(x, y int) (rune, []rune, tcell.Style, int) {
+    sx, sy, ok := vs.translate(x, y)
+    if !ok {
+        return ' ', nil, tcell.StyleDefault, 1
+    }
+
+    return vs.Screen.GetContent(sx, sy)
+}